@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/database"
+
+	authInfra "bobshop/internal/modules/auth/infrastructure"
+	categoryInfra "bobshop/internal/modules/category/infrastructure"
+	productInfra "bobshop/internal/modules/product/infrastructure"
+)
+
+// migrations lists every module's migrations; RunMigrations orders them.
+func migrations(cfg *config.Config) []database.Migration {
+	return slices.Concat(
+		authInfra.Migrations(&cfg.Auth),
+		productInfra.Migrations,
+		categoryInfra.Migrations,
+	)
+}
+
+func main() {
+	envPath := flag.String("env", ".env", "env file path")
+	configPath := flag.String("config", "./configs/config.dev.yaml", "config file path")
+	flag.Parse()
+	if err := run(*envPath, *configPath); err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+}
+
+func run(envPath, configPath string) error {
+	cfg, err := config.LoadConfig(envPath, configPath)
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := database.ConnectMongo(&cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	return database.RunMigrations(ctx, database.ProvideMongoDatabase(client, &cfg.Database), migrations(cfg))
+}
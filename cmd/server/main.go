@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 
 	"bobshop/internal/platform/config"
 	"bobshop/internal/platform/response"
+	"bobshop/pkg/auth"
 )
 
 func main() {
@@ -24,12 +26,29 @@ func main() {
 	// Configure toggles exposing backend error details (enable only in development).
 	response.Configure(config.IsDevelopment())
 
+	hashing := cfg.Auth.PasswordHashing
+	auth.ConfigurePasswordHashing(auth.PasswordHashParams{
+		Algorithm:         hashing.Algorithm,
+		BcryptCost:        hashing.BcryptCost,
+		Argon2Memory:      hashing.Memory,
+		Argon2Iterations:  hashing.Iterations,
+		Argon2Parallelism: hashing.Parallelism,
+		Argon2SaltLength:  hashing.SaltLength,
+		Argon2KeyLength:   hashing.KeyLength,
+	})
+
 	app, cleanup, err := buildApp(cfg)
 	if err != nil {
 		log.Fatalf("could not initialize server: %v", err)
 	}
 	defer cleanup()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, worker := range app.Workers {
+		go worker(ctx)
+	}
+
 	// Graceful shutdown (SIGINT, SIGTERM)
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
@@ -37,6 +56,7 @@ func main() {
 	go func() {
 		<-c
 		log.Println("Shutting down gracefully...")
+		cancel()
 		cleanup()
 		os.Exit(0)
 	}()
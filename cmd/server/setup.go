@@ -1,19 +1,44 @@
 package main
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/privacy"
 	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/security"
 
+	apiKeyHttp "bobshop/internal/modules/apikey/delivery/http"
+	authApp "bobshop/internal/modules/auth/application"
 	authHttp "bobshop/internal/modules/auth/delivery/http"
+	categoryHttp "bobshop/internal/modules/category/delivery/http"
+	privacyApp "bobshop/internal/modules/privacy/application"
+	privacyHttp "bobshop/internal/modules/privacy/delivery/http"
+	productApp "bobshop/internal/modules/product/application"
 	productHttp "bobshop/internal/modules/product/delivery/http"
 )
 
+// Worker is a background job that runs until its context is cancelled.
+type Worker func(ctx context.Context)
+
 type AppServer struct {
-	Engine *gin.Engine
+	Engine  *gin.Engine
+	Workers []Worker
+}
+
+// provideDataSubjects lists the modules holding personal data in erasure
+// order. The account goes last so a failed erasure can be retried.
+func provideDataSubjects(
+	account *authApp.UserDataSubject,
+	authEvents *authApp.AuthEventDataSubject,
+	passkeys *authApp.PasskeyDataSubject,
+	products *productApp.ProductDataSubject,
+) []privacy.DataSubject {
+	return []privacy.DataSubject{products, authEvents, passkeys, account}
 }
 
 func provideGinEngine(cfg *config.ServerConfig) *gin.Engine {
@@ -38,11 +63,24 @@ func provideGinEngine(cfg *config.ServerConfig) *gin.Engine {
 
 func initializeServer(
 	engine *gin.Engine,
+	csrfCfg *config.CSRFConfig,
 	authMiddleware gin.HandlerFunc,
+	keySet security.KeySetProvider,
 	authHandler *authHttp.AuthHandler,
+	apiKeyHandler *apiKeyHttp.APIKeyHandler,
+	categoryHandler *categoryHttp.CategoryHandler,
 	productHandler *productHttp.ProductHandler,
+	privacyHandler *privacyHttp.PrivacyHandler,
+	privacyService *privacyApp.PrivacyService,
+	productService *productApp.ProductService,
 ) *AppServer {
 	// Register global middleware here if any
+	engine.Use(middleware.CSRFProtection(csrfCfg))
+
+	// Public keys for verifying our tokens, served raw as RFC 7517 requires
+	engine.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, keySet.JWKS())
+	})
 
 	// Register routes
 	apiV1 := engine.Group("/api/v1")
@@ -53,12 +91,25 @@ func initializeServer(
 	})
 
 	// auth routes
-	authHttp.RegisterRoutes(apiV1, authHandler)
+	authHttp.RegisterRoutes(apiV1, authMiddleware, authHandler)
+
+	// api key routes
+	apiKeyHttp.RegisterRoutes(apiV1, authMiddleware, apiKeyHandler)
+
+	// category routes
+	categoryHttp.RegisterRoutes(apiV1, authMiddleware, categoryHandler)
 
 	// product routes
 	productHttp.RegisterRoutes(apiV1, authMiddleware, productHandler)
 
+	// data subject request routes
+	privacyHttp.RegisterRoutes(apiV1, authMiddleware, privacyHandler)
+
 	return &AppServer{
 		Engine: engine,
+		Workers: []Worker{
+			privacyService.RunDeletionWorker,
+			productService.RunSuggestIndexer,
+		},
 	}
 }
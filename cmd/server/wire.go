@@ -6,12 +6,20 @@ package main
 import (
 	"github.com/google/wire"
 
+	"bobshop/internal/modules/apikey"
 	"bobshop/internal/modules/auth"
+	"bobshop/internal/modules/category"
+	categoryApp "bobshop/internal/modules/category/application"
+	categoryDomain "bobshop/internal/modules/category/domain"
+	"bobshop/internal/modules/privacy"
 	"bobshop/internal/modules/product"
+	productApp "bobshop/internal/modules/product/application"
+	productDomain "bobshop/internal/modules/product/domain"
 	"bobshop/internal/platform/config"
 	"bobshop/internal/platform/database"
 	"bobshop/internal/platform/infrastructure"
 	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/pagination"
 	"bobshop/internal/platform/security"
 	"bobshop/internal/platform/web"
 )
@@ -19,8 +27,9 @@ import (
 func buildApp(cfg *config.Config) (*AppServer, func(), error) {
 	panic(wire.Build(
 		// Config
-		wire.FieldsOf(new(*config.Config), "Server", "Database", "Redis", "Cookie", "JWT"),
+		wire.FieldsOf(new(*config.Config), "Server", "Database", "Redis", "Cookie", "CSRF", "JWT", "Auth", "Mail", "OIDC", "Privacy", "Pagination", "Search"),
 		wire.Bind(new(security.Tokenizer), new(*infrastructure.JwtTokenizer)),
+		wire.Bind(new(security.KeySetProvider), new(*infrastructure.JwtTokenizer)),
 
 		// Infrastructure
 		database.ConnectMongo,
@@ -30,15 +39,27 @@ func buildApp(cfg *config.Config) (*AppServer, func(), error) {
 		// JWT
 		infrastructure.NewJwtTokenizer,
 
+		// Mail
+		infrastructure.NewMailer,
+
 		// Cookie
 		web.NewCookieManager,
 
+		// Pagination
+		pagination.NewCursorCodec,
+
 		// Middlewares
 		middleware.AuthMiddleware,
 
 		// Modules
 		auth.AuthSet,
+		apikey.APIKeySet,
+		category.CategorySet,
 		product.ProductSet,
+		wire.Bind(new(productDomain.CategoryCatalog), new(*categoryApp.CategoryDirectory)),
+		wire.Bind(new(categoryDomain.CategoryListener), new(*productApp.ProductService)),
+		privacy.PrivacySet,
+		provideDataSubjects,
 
 		// Presentation
 		provideGinEngine,
@@ -7,16 +7,26 @@
 package main
 
 import (
-	"bobshop/internal/modules/auth/application"
+	"bobshop/internal/modules/apikey/application"
+	http2 "bobshop/internal/modules/apikey/delivery/http"
+	infrastructure3 "bobshop/internal/modules/apikey/infrastructure"
+	application2 "bobshop/internal/modules/auth/application"
 	"bobshop/internal/modules/auth/delivery/http"
 	infrastructure2 "bobshop/internal/modules/auth/infrastructure"
-	application2 "bobshop/internal/modules/product/application"
-	http2 "bobshop/internal/modules/product/delivery/http"
-	infrastructure3 "bobshop/internal/modules/product/infrastructure"
+	application3 "bobshop/internal/modules/category/application"
+	http3 "bobshop/internal/modules/category/delivery/http"
+	infrastructure4 "bobshop/internal/modules/category/infrastructure"
+	application5 "bobshop/internal/modules/privacy/application"
+	http5 "bobshop/internal/modules/privacy/delivery/http"
+	infrastructure6 "bobshop/internal/modules/privacy/infrastructure"
+	application4 "bobshop/internal/modules/product/application"
+	http4 "bobshop/internal/modules/product/delivery/http"
+	infrastructure5 "bobshop/internal/modules/product/infrastructure"
 	"bobshop/internal/platform/config"
 	"bobshop/internal/platform/database"
 	"bobshop/internal/platform/infrastructure"
 	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/pagination"
 	"bobshop/internal/platform/web"
 )
 
@@ -25,31 +35,84 @@ import (
 func buildApp(cfg *config.Config) (*AppServer, func(), error) {
 	serverConfig := &cfg.Server
 	engine := provideGinEngine(serverConfig)
+	csrfConfig := &cfg.CSRF
 	jwtConfig := &cfg.JWT
-	jwtTokenizer := infrastructure.NewJwtTokenizer(jwtConfig)
-	handlerFunc := middleware.AuthMiddleware(jwtTokenizer)
+	jwtTokenizer, err := infrastructure.NewJwtTokenizer(jwtConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	redisConfig := &cfg.Redis
+	client, cleanup, err := database.ConnectRedis(redisConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	redisSessionStore := infrastructure2.NewRedisSessionStore(client)
 	databaseConfig := &cfg.Database
-	client, cleanup, err := database.ConnectMongo(databaseConfig)
+	mongoClient, cleanup2, err := database.ConnectMongo(databaseConfig)
 	if err != nil {
+		cleanup()
 		return nil, nil, err
 	}
-	mongoDatabase := database.ProvideMongoDatabase(client, databaseConfig)
+	mongoDatabase := database.ProvideMongoDatabase(mongoClient, databaseConfig)
+	mongoAPIKeyRepository := infrastructure3.NewMongoAPIKeyRepository(mongoDatabase)
+	apiKeyService := application.NewAPIKeyService(mongoAPIKeyRepository)
+	authConfig := &cfg.Auth
+	handlerFunc := middleware.AuthMiddleware(jwtTokenizer, redisSessionStore, apiKeyService, authConfig)
 	mongoAuthRepository := infrastructure2.NewMongoAuthRepository(mongoDatabase)
-	authService := application.NewAuthService(mongoAuthRepository, jwtTokenizer)
+	mongoAuthEventRepository, err := infrastructure2.NewMongoAuthEventRepository(mongoDatabase, authConfig)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	redisRefreshTokenStore := infrastructure2.NewRedisRefreshTokenStore(client)
+	redisActionTokenStore := infrastructure2.NewRedisActionTokenStore(client)
+	redisLoginAttemptStore := infrastructure2.NewRedisLoginAttemptStore(client)
+	oidcConfig := &cfg.OIDC
+	httpoidcClient := infrastructure2.NewHTTPOIDCClient(oidcConfig)
+	redisOIDCStateStore := infrastructure2.NewRedisOIDCStateStore(client)
+	redisWebAuthnChallengeStore := infrastructure2.NewRedisWebAuthnChallengeStore(client)
+	fileBreachedPasswordChecker := infrastructure2.NewFileBreachedPasswordChecker(authConfig)
+	mailConfig := &cfg.Mail
+	mailer, err := infrastructure.NewMailer(mailConfig, mongoDatabase)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	authService := application2.NewAuthService(mongoAuthRepository, mongoAuthEventRepository, redisRefreshTokenStore, redisSessionStore, redisActionTokenStore, redisLoginAttemptStore, httpoidcClient, redisOIDCStateStore, redisWebAuthnChallengeStore, fileBreachedPasswordChecker, jwtTokenizer, mailer, jwtConfig, authConfig, oidcConfig)
 	cookieConfig := &cfg.Cookie
 	cookieManager := web.NewCookieManager(cookieConfig)
-	authHandler := http.NewAuthHandler(authService, cookieManager)
-	mongoProductRepository := infrastructure3.NewMongoProductRepository(mongoDatabase)
-	redisConfig := &cfg.Redis
-	redisClient, cleanup2, err := database.ConnectRedis(redisConfig)
+	authHandler := http.NewAuthHandler(authService, cookieManager, oidcConfig)
+	apiKeyHandler := http2.NewAPIKeyHandler(apiKeyService)
+	mongoCategoryRepository := infrastructure4.NewMongoCategoryRepository(mongoDatabase)
+	mongoProductRepository := infrastructure5.NewMongoProductRepository(mongoDatabase)
+	mongoSearcher := infrastructure5.NewMongoSearcher(mongoDatabase)
+	redisSuggestIndex := infrastructure5.NewRedisSuggestIndex(client)
+	categoryDirectory := application3.NewCategoryDirectory(mongoCategoryRepository)
+	redisCache := infrastructure5.NewRedisCache(client)
+	paginationConfig := &cfg.Pagination
+	cursorCodec, err := pagination.NewCursorCodec(paginationConfig)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	redisCache := infrastructure3.NewRedisCache(redisClient)
-	productService := application2.NewProductService(mongoProductRepository, redisCache)
-	productHandler := http2.NewProductHandler(productService)
-	appServer := initializeServer(engine, handlerFunc, authHandler, productHandler)
+	searchConfig := &cfg.Search
+	productService := application4.NewProductService(mongoProductRepository, mongoSearcher, redisSuggestIndex, categoryDirectory, redisCache, cursorCodec, searchConfig)
+	categoryService := application3.NewCategoryService(mongoCategoryRepository, productService)
+	categoryHandler := http3.NewCategoryHandler(categoryService)
+	productHandler := http4.NewProductHandler(productService)
+	mongoDeletionRequestRepository := infrastructure6.NewMongoDeletionRequestRepository(mongoDatabase)
+	userDataSubject := application2.NewUserDataSubject(mongoAuthRepository, redisSessionStore, redisLoginAttemptStore)
+	authEventDataSubject := application2.NewAuthEventDataSubject(mongoAuthRepository, mongoAuthEventRepository)
+	passkeyDataSubject := application2.NewPasskeyDataSubject(mongoAuthRepository)
+	productDataSubject := application4.NewProductDataSubject(mongoProductRepository, redisCache)
+	v := provideDataSubjects(userDataSubject, authEventDataSubject, passkeyDataSubject, productDataSubject)
+	privacyConfig := &cfg.Privacy
+	privacyService := application5.NewPrivacyService(mongoDeletionRequestRepository, v, privacyConfig)
+	privacyHandler := http5.NewPrivacyHandler(privacyService)
+	appServer := initializeServer(engine, csrfConfig, handlerFunc, jwtTokenizer, authHandler, apiKeyHandler, categoryHandler, productHandler, privacyHandler, privacyService, productService)
 	return appServer, func() {
 		cleanup2()
 		cleanup()
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/apikey/delivery/http/dto"
+	"bobshop/internal/modules/apikey/domain"
+	"bobshop/internal/platform/security"
+)
+
+type APIKeyService struct {
+	repo domain.APIKeyRepository
+}
+
+func NewAPIKeyService(repo domain.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create returns the stored key and its plain value.
+func (s *APIKeyService) Create(ctx context.Context, req dto.CreateRequest, createdBy uuid.UUID) (*domain.APIKey, string, error) {
+	for _, scope := range req.Scopes {
+		if !security.IsAPIKeyScope(security.Permission(scope)) {
+			return nil, "", domain.ErrInvalidScope
+		}
+	}
+
+	key, plain, err := domain.NewAPIKey(req.Name, req.Scopes, req.ExpiresAt, createdBy)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Revoke(ctx, id, time.Now())
+}
+
+// AuthenticateAPIKey implements security.APIKeyAuthenticator.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, plain string) (*security.Principal, error) {
+	if !domain.LooksLikeKey(plain) {
+		return nil, security.ErrInvalidAPIKey
+	}
+	key, err := s.repo.FindByHash(ctx, domain.HashKey(plain))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, security.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, security.ErrInvalidAPIKey
+	}
+	if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		return nil, err
+	}
+
+	scopes := make([]security.Permission, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = security.Permission(scope)
+	}
+	return &security.Principal{
+		ID:     key.ID.String(),
+		Name:   key.Name,
+		Scopes: scopes,
+	}, nil
+}
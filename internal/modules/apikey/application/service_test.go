@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/apikey/delivery/http/dto"
+	"bobshop/internal/modules/apikey/domain"
+	"bobshop/internal/platform/security"
+)
+
+type memKeys struct {
+	keys    map[uuid.UUID]*domain.APIKey
+	touched map[uuid.UUID]time.Time
+}
+
+func newMemKeys() *memKeys {
+	return &memKeys{keys: map[uuid.UUID]*domain.APIKey{}, touched: map[uuid.UUID]time.Time{}}
+}
+
+func (m *memKeys) Create(_ context.Context, key *domain.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *memKeys) FindByHash(_ context.Context, hash string) (*domain.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *memKeys) List(context.Context) ([]*domain.APIKey, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *memKeys) Revoke(_ context.Context, id uuid.UUID, at time.Time) error {
+	key, ok := m.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &at
+	return nil
+}
+
+func (m *memKeys) TouchLastUsed(_ context.Context, id uuid.UUID, at time.Time) error {
+	m.touched[id] = at
+	return nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		revoke    bool
+		present   func(plain string) string
+		wantErr   error
+	}{
+		{name: "active key", present: func(plain string) string { return plain }},
+		{name: "expires later", expiresAt: &future, present: func(plain string) string { return plain }},
+		{name: "expired", expiresAt: &past, present: func(plain string) string { return plain }, wantErr: security.ErrInvalidAPIKey},
+		{name: "revoked", revoke: true, present: func(plain string) string { return plain }, wantErr: security.ErrInvalidAPIKey},
+		{name: "unknown key", present: func(plain string) string { return plain + "x" }, wantErr: security.ErrInvalidAPIKey},
+		{name: "not a key", present: func(string) string { return "Bearer abc" }, wantErr: security.ErrInvalidAPIKey},
+		// The stored hash must not work as a key in its own right.
+		{name: "hash", present: func(plain string) string { return domain.HashKey(plain) }, wantErr: security.ErrInvalidAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemKeys()
+			s := NewAPIKeyService(repo)
+			key, plain, err := s.Create(ctx, dto.CreateRequest{
+				Name:      "feed",
+				Scopes:    []string{string(security.PermProductWrite)},
+				ExpiresAt: tt.expiresAt,
+			}, uuid.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoke {
+				if err := s.Revoke(ctx, key.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			principal, err := s.AuthenticateAPIKey(ctx, tt.present(plain))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateAPIKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, touched := repo.touched[key.ID]; touched != (err == nil) {
+				t.Errorf("last use recorded = %v, want %v", touched, err == nil)
+			}
+			if err != nil {
+				return
+			}
+			want := &security.Principal{ID: key.ID.String(), Name: "feed", Scopes: []security.Permission{security.PermProductWrite}}
+			if !reflect.DeepEqual(principal, want) {
+				t.Errorf("AuthenticateAPIKey() = %+v, want %+v", principal, want)
+			}
+		})
+	}
+}
+
+func TestCreateScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr error
+	}{
+		{name: "api key scopes", scopes: []string{string(security.PermProductWrite), string(security.PermCategoryWrite)}},
+		{name: "user management", scopes: []string{string(security.PermUserManage)}, wantErr: domain.ErrInvalidScope},
+		{name: "key management", scopes: []string{string(security.PermAPIKeyManage)}, wantErr: domain.ErrInvalidScope},
+		{name: "unknown scope", scopes: []string{"root"}, wantErr: domain.ErrInvalidScope},
+		{
+			name:    "one bad scope",
+			scopes:  []string{string(security.PermProductWrite), string(security.PermAuditRead)},
+			wantErr: domain.ErrInvalidScope,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemKeys()
+			_, _, err := NewAPIKeyService(repo).Create(context.Background(), dto.CreateRequest{Name: "feed", Scopes: tt.scopes}, uuid.New())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if stored := len(repo.keys) == 1; stored != (tt.wantErr == nil) {
+				t.Errorf("key stored = %v, want %v", stored, tt.wantErr == nil)
+			}
+		})
+	}
+}
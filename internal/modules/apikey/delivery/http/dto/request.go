@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type CreateRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+}
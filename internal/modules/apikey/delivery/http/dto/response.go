@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/apikey/domain"
+)
+
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func ToAPIKeyResponse(k *domain.APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
+
+func ToAPIKeyResponses(keys []*domain.APIKey) []*APIKeyResponse {
+	res := make([]*APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, ToAPIKeyResponse(k))
+	}
+	return res
+}
+
+// CreateResponse is the only place the plain key is ever returned.
+type CreateResponse struct {
+	*APIKeyResponse
+	Key string `json:"key"`
+}
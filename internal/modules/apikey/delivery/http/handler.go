@@ -0,0 +1,82 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"bobshop/internal/modules/apikey/application"
+	"bobshop/internal/modules/apikey/delivery/http/dto"
+	"bobshop/internal/modules/apikey/domain"
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/web"
+)
+
+type APIKeyHandler struct {
+	service  *application.APIKeyService
+	validate *validator.Validate
+}
+
+func NewAPIKeyHandler(service *application.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	var req dto.CreateRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	key, plain, err := h.service.Create(c.Request.Context(), req, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidScope) {
+			response.BadRequest(c, "invalid scope", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Created(c, "API key created", dto.CreateResponse{
+		APIKeyResponse: dto.ToAPIKeyResponse(key),
+		Key:            plain,
+	})
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API keys listed", dto.ToAPIKeyResponses(keys))
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "API key revoked")
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/security"
+)
+
+func RegisterRoutes(group *gin.RouterGroup, authMiddleware gin.HandlerFunc, h *APIKeyHandler) {
+	keys := group.Group("/admin/api-keys",
+		authMiddleware,
+		middleware.RequireUser(),
+		middleware.RequirePermission(security.PermAPIKeyManage),
+	)
+	{
+		keys.POST("", h.Create)
+		keys.GET("", h.List)
+		keys.DELETE("/:id", h.Revoke)
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	key, plain, err := NewAPIKey("feed", []string{"product:write"}, nil, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !LooksLikeKey(plain) {
+		t.Errorf("plain key %q lacks the %q prefix", plain, keyPrefix)
+	}
+	if key.Hash != HashKey(plain) {
+		t.Error("stored hash does not match the plain key")
+	}
+	if strings.Contains(key.Hash, plain) || key.Hash == plain {
+		t.Error("plain key stored")
+	}
+	if !strings.HasPrefix(plain, key.Prefix) || len(key.Prefix) != keyPrefixChars {
+		t.Errorf("prefix = %q, want the first %d characters of the key", key.Prefix, keyPrefixChars)
+	}
+
+	_, other, err := NewAPIKey("feed", nil, nil, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == plain || HashKey(other) == key.Hash {
+		t.Error("two keys share a value")
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		key  APIKey
+		want bool
+	}{
+		{name: "no expiry", key: APIKey{}, want: true},
+		{name: "expires later", key: APIKey{ExpiresAt: &future}, want: true},
+		{name: "expired", key: APIKey{ExpiresAt: &past}},
+		{name: "expires now", key: APIKey{ExpiresAt: &now}},
+		{name: "revoked", key: APIKey{RevokedAt: &past}},
+		{name: "revoked before expiry", key: APIKey{ExpiresAt: &future, RevokedAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.IsActive(now); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package domain
+
+import "errors"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidScope   = errors.New("scope cannot be granted to an api key")
+)
@@ -0,0 +1,19 @@
+package apikey
+
+import (
+	"github.com/google/wire"
+
+	"bobshop/internal/modules/apikey/application"
+	"bobshop/internal/modules/apikey/delivery/http"
+	"bobshop/internal/modules/apikey/domain"
+	"bobshop/internal/modules/apikey/infrastructure"
+	"bobshop/internal/platform/security"
+)
+
+var APIKeySet = wire.NewSet(
+	wire.Bind(new(domain.APIKeyRepository), new(*infrastructure.MongoAPIKeyRepository)),
+	wire.Bind(new(security.APIKeyAuthenticator), new(*application.APIKeyService)),
+	infrastructure.NewMongoAPIKeyRepository,
+	application.NewAPIKeyService,
+	http.NewAPIKeyHandler,
+)
@@ -0,0 +1,94 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/mail"
+)
+
+const (
+	verifyEmailPath   = "/verify-email"
+	resetPasswordPath = "/reset-password"
+)
+
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	ttl := s.authCfg.VerificationTokenTTL
+	token, err := s.issueActionToken(ctx, domain.PurposeEmailVerification, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Confirm your email address by opening the link below. It expires in %s.\n\n%s\n",
+			ttl, s.buildLink(verifyEmailPath, token),
+		),
+	})
+}
+
+// sendPasswordResetEmail replaces any reset link sent before, so only the
+// newest one works.
+func (s *AuthService) sendPasswordResetEmail(ctx context.Context, user *domain.User) error {
+	if err := s.actionTokenStore.RevokeAll(ctx, domain.PurposePasswordReset, user.ID); err != nil {
+		return err
+	}
+	ttl := s.authCfg.PasswordResetTokenTTL
+	token, err := s.issueActionToken(ctx, domain.PurposePasswordReset, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Choose a new password by opening the link below. It expires in %s.\n"+
+				"If you did not ask for a reset you can ignore this email.\n\n%s\n",
+			ttl, s.buildLink(resetPasswordPath, token),
+		),
+	})
+}
+
+func (s *AuthService) issueActionToken(
+	ctx context.Context,
+	purpose domain.TokenPurpose,
+	userID uuid.UUID,
+	ttl time.Duration,
+) (string, error) {
+	token, plain, err := domain.NewActionToken(purpose, userID, ttl, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return "", err
+	}
+	if err := s.actionTokenStore.Save(ctx, token); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (s *AuthService) consumeActionToken(ctx context.Context, purpose domain.TokenPurpose, plain string) (uuid.UUID, error) {
+	hash, err := domain.VerifyActionToken(purpose, plain, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return s.actionTokenStore.Consume(ctx, purpose, hash)
+}
+
+func (s *AuthService) peekActionToken(ctx context.Context, purpose domain.TokenPurpose, plain string) (uuid.UUID, error) {
+	hash, err := domain.VerifyActionToken(purpose, plain, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return s.actionTokenStore.Peek(ctx, purpose, hash)
+}
+
+func (s *AuthService) buildLink(path, token string) string {
+	return s.authCfg.LinkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
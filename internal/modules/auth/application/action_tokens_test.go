@@ -0,0 +1,351 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/mail"
+)
+
+const testPassword = "correct horse battery staple"
+
+type memActionTokens map[string]*domain.ActionToken
+
+func (m memActionTokens) Save(_ context.Context, token *domain.ActionToken) error {
+	m[token.Hash] = token
+	return nil
+}
+
+func (m memActionTokens) Consume(ctx context.Context, purpose domain.TokenPurpose, hash string) (uuid.UUID, error) {
+	userID, err := m.Peek(ctx, purpose, hash)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	delete(m, hash)
+	return userID, nil
+}
+
+func (m memActionTokens) Peek(_ context.Context, purpose domain.TokenPurpose, hash string) (uuid.UUID, error) {
+	token, ok := m[hash]
+	if !ok || token.Purpose != purpose || time.Now().After(token.ExpiresAt) {
+		return uuid.Nil, domain.ErrInvalidActionToken
+	}
+	return token.UserID, nil
+}
+
+func (m memActionTokens) RevokeAll(_ context.Context, purpose domain.TokenPurpose, userID uuid.UUID) error {
+	for hash, token := range m {
+		if token.Purpose == purpose && token.UserID == userID {
+			delete(m, hash)
+		}
+	}
+	return nil
+}
+
+type sentMail []mail.Message
+
+func (m *sentMail) Send(_ context.Context, msg mail.Message) error {
+	*m = append(*m, msg)
+	return nil
+}
+
+// token returns the token in the link of the last message.
+func (m sentMail) token(t *testing.T) string {
+	t.Helper()
+	if len(m) == 0 {
+		t.Fatal("no mail sent")
+	}
+	_, rest, ok := strings.Cut(m[len(m)-1].Body, "?token=")
+	if !ok {
+		t.Fatal("mail has no link")
+	}
+	token, err := url.QueryUnescape(strings.TrimSpace(rest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type nopBreaches struct{}
+
+func (nopBreaches) IsBreached(context.Context, string) (bool, error) { return false, nil }
+
+type accountFixture struct {
+	service  *AuthService
+	user     *domain.User
+	sessions *memSessions
+	tokens   memActionTokens
+	mail     *sentMail
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	user := newTestUser(t, "ann@example.com")
+	f := &accountFixture{
+		user:     user,
+		sessions: newMemSessions(),
+		tokens:   memActionTokens{},
+		mail:     &sentMail{},
+	}
+	f.service = newSessionService(newMemUsers(user), f.sessions, newMemRefreshTokens())
+	f.service.actionTokenStore = f.tokens
+	f.service.breachChecker = nopBreaches{}
+	f.service.mailer = f.mail
+	f.service.authCfg = &config.AuthConfig{
+		TokenSecret:           "test-secret",
+		LinkBaseURL:           "https://shop.example.com",
+		VerificationTokenTTL:  time.Hour,
+		PasswordResetTokenTTL: time.Hour,
+	}
+	return f
+}
+
+// signIn opens a session for the fixture's user and returns its ID.
+func (f *accountFixture) signIn(t *testing.T) uuid.UUID {
+	t.Helper()
+	session, err := domain.NewSession(f.user.ID, domain.DeviceInfo{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.sessions.Create(context.Background(), session, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	return session.ID
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		token        func(t *testing.T, f *accountFixture) string
+		wantErr      error
+		wantVerified bool
+	}{
+		{
+			name: "mailed token",
+			token: func(t *testing.T, f *accountFixture) string {
+				return f.mail.token(t)
+			},
+			wantVerified: true,
+		},
+		{
+			name: "used token",
+			token: func(t *testing.T, f *accountFixture) string {
+				token := f.mail.token(t)
+				if err := f.service.VerifyEmail(ctx, token); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr:      domain.ErrInvalidActionToken,
+			wantVerified: true,
+		},
+		{
+			name: "tampered token",
+			token: func(t *testing.T, f *accountFixture) string {
+				return f.mail.token(t) + "x"
+			},
+			wantErr: domain.ErrInvalidActionToken,
+		},
+		{
+			name: "password reset token",
+			token: func(t *testing.T, f *accountFixture) string {
+				if err := f.service.ForgotPassword(ctx, f.user.Email); err != nil {
+					t.Fatal(err)
+				}
+				return f.mail.token(t)
+			},
+			wantErr: domain.ErrInvalidActionToken,
+		},
+		{
+			name: "expired token",
+			token: func(t *testing.T, f *accountFixture) string {
+				token := f.mail.token(t)
+				for _, stored := range f.tokens {
+					stored.ExpiresAt = time.Now().Add(-time.Second)
+				}
+				return token
+			},
+			wantErr: domain.ErrInvalidActionToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+			if err := f.service.ResendVerification(ctx, f.user.Email); err != nil {
+				t.Fatal(err)
+			}
+
+			err := f.service.VerifyEmail(ctx, tt.token(t, f))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyEmail() error = %v, want %v", err, tt.wantErr)
+			}
+			if f.user.IsVerified() != tt.wantVerified {
+				t.Errorf("verified = %v, want %v", f.user.IsVerified(), tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		email    string
+		verified bool
+		wantMail int
+	}{
+		{name: "unverified", email: "ann@example.com", wantMail: 1},
+		{name: "email case", email: "Ann@Example.com", wantMail: 1},
+		{name: "already verified", email: "ann@example.com", verified: true},
+		{name: "unknown email", email: "bob@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+			if tt.verified {
+				f.user.MarkVerified()
+			}
+			if err := f.service.ResendVerification(ctx, tt.email); err != nil {
+				t.Fatalf("ResendVerification() error = %v", err)
+			}
+			if len(*f.mail) != tt.wantMail {
+				t.Errorf("sent %d mails, want %d", len(*f.mail), tt.wantMail)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	const newPassword = "a different long passphrase"
+
+	tests := []struct {
+		name     string
+		token    func(t *testing.T, f *accountFixture) string
+		password string
+		wantErr  error
+		// wantRetry reports whether the token still works afterwards.
+		wantRetry bool
+	}{
+		{
+			name:     "mailed token",
+			token:    func(t *testing.T, f *accountFixture) string { return f.mail.token(t) },
+			password: newPassword,
+		},
+		{
+			name:      "weak password keeps the token",
+			token:     func(t *testing.T, f *accountFixture) string { return f.mail.token(t) },
+			password:  "short",
+			wantErr:   domain.ErrWeakPassword,
+			wantRetry: true,
+		},
+		{
+			name: "superseded token",
+			token: func(t *testing.T, f *accountFixture) string {
+				token := f.mail.token(t)
+				if err := f.service.ForgotPassword(ctx, f.user.Email); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			password: newPassword,
+			wantErr:  domain.ErrInvalidActionToken,
+		},
+		{
+			name: "verification token",
+			token: func(t *testing.T, f *accountFixture) string {
+				if err := f.service.ResendVerification(ctx, f.user.Email); err != nil {
+					t.Fatal(err)
+				}
+				return f.mail.token(t)
+			},
+			password: newPassword,
+			wantErr:  domain.ErrInvalidActionToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+			f.signIn(t)
+			f.signIn(t)
+			if err := f.service.ForgotPassword(ctx, f.user.Email); err != nil {
+				t.Fatal(err)
+			}
+			token := tt.token(t, f)
+
+			err := f.service.ResetPassword(ctx, token, tt.password, domain.DeviceInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetPassword() error = %v, want %v", err, tt.wantErr)
+			}
+			reset := tt.wantErr == nil
+			if got := f.user.CheckPassword(newPassword); got != reset {
+				t.Errorf("new password works = %v, want %v", got, reset)
+			}
+			if got := len(f.sessions.sessions) == 0; got != reset {
+				t.Errorf("sessions revoked = %v, want %v", got, reset)
+			}
+
+			err = f.service.ResetPassword(ctx, token, newPassword, domain.DeviceInfo{})
+			if got := err == nil; got != tt.wantRetry {
+				t.Errorf("second ResetPassword() error = %v, want token usable %v", err, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	f := newAccountFixture(t)
+	if err := f.service.ForgotPassword(context.Background(), "bob@example.com"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	if len(*f.mail) != 0 {
+		t.Errorf("sent %d mails for an unknown email", len(*f.mail))
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	const newPassword = "a different long passphrase"
+
+	tests := []struct {
+		name     string
+		current  string
+		password string
+		wantErr  error
+	}{
+		{name: "changed", current: testPassword, password: newPassword},
+		{name: "wrong current password", current: "guess", password: newPassword, wantErr: domain.ErrInvalidPassword},
+		{name: "weak password", current: testPassword, password: "short", wantErr: domain.ErrWeakPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+			current := f.signIn(t)
+			other := f.signIn(t)
+
+			err := f.service.ChangePassword(ctx, f.user.ID, current, tt.current, tt.password, domain.DeviceInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+			changed := tt.wantErr == nil
+			if got := f.user.CheckPassword(newPassword); got != changed {
+				t.Errorf("new password works = %v, want %v", got, changed)
+			}
+			if _, ok := f.sessions.sessions[current]; !ok {
+				t.Error("current session was revoked")
+			}
+			if _, ok := f.sessions.sessions[other]; ok == changed {
+				t.Errorf("other session kept = %v, want %v", ok, !changed)
+			}
+		})
+	}
+}
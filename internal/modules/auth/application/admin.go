@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const adminAuditLimit = 100
+
+func (s *AuthService) ListUsers(
+	ctx context.Context,
+	filter domain.UserFilter,
+	cursor *uuid.UUID,
+	limit int,
+) ([]*domain.User, *uuid.UUID, error) {
+	return s.userRepo.List(ctx, filter, cursor, limit)
+}
+
+func (s *AuthService) GetUser(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	return s.userRepo.FindByID(ctx, userID)
+}
+
+// ChangeRole signs the user out everywhere so the new role applies at once
+// rather than when their access token next refreshes. Like every admin action
+// it is audited as soon as the account is saved, so a failure in what follows
+// cannot leave the change unrecorded.
+func (s *AuthService) ChangeRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) error {
+	if actorID == userID {
+		return domain.ErrCannotModifySelf
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	previous := user.Role
+	if previous == role {
+		return nil
+	}
+	user.ChangeRole(role)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	event := adminEvent(domain.EventRoleChange, actorID, userID, previous.String()+" -> "+role.String())
+	if err := s.recordAdminAction(ctx, event); err != nil {
+		return err
+	}
+	return s.sessionStore.RevokeAll(ctx, user.ID)
+}
+
+// DisableUser blocks sign-in and revokes every session, which makes the auth
+// middleware reject the user's outstanding access tokens.
+func (s *AuthService) DisableUser(ctx context.Context, actorID, userID uuid.UUID, reason string) error {
+	if actorID == userID {
+		return domain.ErrCannotModifySelf
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.Disable(reason)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := s.recordAdminAction(ctx, adminEvent(domain.EventAccountDisable, actorID, userID, reason)); err != nil {
+		return err
+	}
+	return s.sessionStore.RevokeAll(ctx, user.ID)
+}
+
+func (s *AuthService) EnableUser(ctx context.Context, actorID, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.Enable()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return s.recordAdminAction(ctx, adminEvent(domain.EventAccountEnable, actorID, userID, ""))
+}
+
+// ForcePasswordReset signs the user out, refuses every sign-in method until
+// they pick a new password and mails them a reset link.
+func (s *AuthService) ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.RequirePasswordReset()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := s.recordAdminAction(ctx, adminEvent(domain.EventPasswordResetForce, actorID, userID, "")); err != nil {
+		return err
+	}
+	if err := s.sessionStore.RevokeAll(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.sendPasswordResetEmail(ctx, user)
+}
+
+// ListAdminAudit returns the latest changes admins made to the user's account.
+func (s *AuthService) ListAdminAudit(ctx context.Context, userID uuid.UUID) ([]*domain.AuthEvent, error) {
+	filter := domain.AuthEventFilter{UserID: &userID, AdminOnly: true}
+	events, _, err := s.eventRepo.List(ctx, filter, nil, adminAuditLimit)
+	return events, err
+}
@@ -0,0 +1,225 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+)
+
+type memEvents struct {
+	domain.AuthEventRepository
+	events []*domain.AuthEvent
+}
+
+func (m *memEvents) Record(_ context.Context, event *domain.AuthEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// adminActions returns the recorded events an admin made.
+func (m *memEvents) adminActions() []*domain.AuthEvent {
+	var actions []*domain.AuthEvent
+	for _, event := range m.events {
+		if event.ActorID != nil {
+			actions = append(actions, event)
+		}
+	}
+	return actions
+}
+
+func newAdminFixture(t *testing.T) (*accountFixture, *memEvents) {
+	t.Helper()
+	f := newAccountFixture(t)
+	events := &memEvents{}
+	f.service.eventRepo = events
+	f.service.attemptStore = newMemAttempts()
+	f.service.authCfg.MFATokenTTL = time.Minute
+	f.signIn(t)
+	f.signIn(t)
+	return f, events
+}
+
+func TestChangeRole(t *testing.T) {
+	ctx := context.Background()
+	admin := uuid.New()
+
+	tests := []struct {
+		name       string
+		actor      func(f *accountFixture) uuid.UUID
+		target     func(f *accountFixture) uuid.UUID
+		role       domain.Role
+		wantErr    error
+		wantRole   domain.Role
+		wantReason string
+	}{
+		{
+			name:       "promote",
+			role:       domain.AdminRole,
+			wantRole:   domain.AdminRole,
+			wantReason: "user -> admin",
+		},
+		{name: "same role", role: domain.UserRole, wantRole: domain.UserRole},
+		{
+			name:     "own account",
+			actor:    func(f *accountFixture) uuid.UUID { return f.user.ID },
+			role:     domain.AdminRole,
+			wantErr:  domain.ErrCannotModifySelf,
+			wantRole: domain.UserRole,
+		},
+		{
+			name:     "unknown user",
+			target:   func(*accountFixture) uuid.UUID { return uuid.New() },
+			role:     domain.AdminRole,
+			wantErr:  domain.ErrUserNotFound,
+			wantRole: domain.UserRole,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, events := newAdminFixture(t)
+			actor, target := admin, f.user.ID
+			if tt.actor != nil {
+				actor = tt.actor(f)
+			}
+			if tt.target != nil {
+				target = tt.target(f)
+			}
+
+			err := f.service.ChangeRole(ctx, actor, target, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangeRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if f.user.Role != tt.wantRole {
+				t.Errorf("role = %s, want %s", f.user.Role, tt.wantRole)
+			}
+
+			changed := tt.wantReason != ""
+			if got := len(f.sessions.sessions) == 0; got != changed {
+				t.Errorf("sessions revoked = %v, want %v", got, changed)
+			}
+			actions := events.adminActions()
+			if len(actions) != map[bool]int{true: 1}[changed] {
+				t.Fatalf("recorded %d admin actions, want one per change", len(actions))
+			}
+			if changed {
+				event := actions[0]
+				if event.Type != domain.EventRoleChange || *event.ActorID != admin || *event.UserID != f.user.ID || event.Reason != tt.wantReason {
+					t.Errorf("event = %+v", event)
+				}
+			}
+		})
+	}
+}
+
+func TestDisableUser(t *testing.T) {
+	ctx := context.Background()
+	f, events := newAdminFixture(t)
+	admin := uuid.New()
+
+	if err := f.service.DisableUser(ctx, f.user.ID, f.user.ID, "oops"); !errors.Is(err, domain.ErrCannotModifySelf) {
+		t.Fatalf("DisableUser() on own account error = %v, want %v", err, domain.ErrCannotModifySelf)
+	}
+	if err := f.service.DisableUser(ctx, admin, f.user.ID, "chargebacks"); err != nil {
+		t.Fatal(err)
+	}
+	if !f.user.IsDisabled() || len(f.sessions.sessions) != 0 {
+		t.Errorf("disabled = %v with %d sessions left, want disabled and signed out", f.user.IsDisabled(), len(f.sessions.sessions))
+	}
+	if actions := events.adminActions(); len(actions) != 1 || actions[0].Type != domain.EventAccountDisable || actions[0].Reason != "chargebacks" {
+		t.Errorf("admin actions = %+v", actions)
+	}
+	if _, err := f.service.SignIn(ctx, f.user.Email, testPassword, domain.DeviceInfo{}); !errors.Is(err, domain.ErrAccountDisabled) {
+		t.Errorf("SignIn() error = %v, want %v", err, domain.ErrAccountDisabled)
+	}
+
+	if err := f.service.EnableUser(ctx, admin, f.user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.SignIn(ctx, f.user.Email, testPassword, domain.DeviceInfo{}); err != nil {
+		t.Errorf("SignIn() after EnableUser() error = %v", err)
+	}
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	const nonce = "browser-nonce"
+
+	tests := []struct {
+		name   string
+		signIn func(t *testing.T, f *accountFixture) error
+	}{
+		{
+			name: "password",
+			signIn: func(t *testing.T, f *accountFixture) error {
+				_, err := f.service.SignIn(ctx, f.user.Email, testPassword, domain.DeviceInfo{})
+				return err
+			},
+		},
+		{
+			name: "magic link",
+			signIn: func(t *testing.T, f *accountFixture) error {
+				if err := f.service.sendMagicLink(ctx, f.user.Email, nonce); err != nil {
+					t.Fatal(err)
+				}
+				_, err := f.service.CompleteMagicLink(ctx, f.mail.token(t), nonce, domain.DeviceInfo{})
+				return err
+			},
+		},
+		{
+			// OIDC and passkey sign-ins end in startSession or, with two
+			// factors, issueMFAToken, like the magic link does.
+			name: "session",
+			signIn: func(t *testing.T, f *accountFixture) error {
+				_, err := f.service.startSession(ctx, f.user, domain.DeviceInfo{}, false)
+				return err
+			},
+		},
+		{
+			name: "second factor",
+			signIn: func(t *testing.T, f *accountFixture) error {
+				_, err := f.service.issueMFAToken(ctx, f.user)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, events := newAdminFixture(t)
+			f.service.oidcCfg = &config.OIDCConfig{RedirectBaseURL: "https://shop.example.com"}
+			admin := uuid.New()
+
+			if err := f.service.ForcePasswordReset(ctx, admin, f.user.ID); err != nil {
+				t.Fatal(err)
+			}
+			if len(f.sessions.sessions) != 0 {
+				t.Errorf("%d sessions left, want all revoked", len(f.sessions.sessions))
+			}
+			if actions := events.adminActions(); len(actions) != 1 || actions[0].Type != domain.EventPasswordResetForce {
+				t.Errorf("admin actions = %+v", actions)
+			}
+			resetToken := f.mail.token(t)
+
+			if err := tt.signIn(t, f); !errors.Is(err, domain.ErrPasswordResetNeeded) {
+				t.Fatalf("sign-in error = %v, want %v", err, domain.ErrPasswordResetNeeded)
+			}
+
+			if err := f.service.ResetPassword(ctx, resetToken, "a different long passphrase", domain.DeviceInfo{}); err != nil {
+				t.Fatal(err)
+			}
+			if f.user.PasswordResetRequired {
+				t.Fatal("ResetPassword() left the reset requirement in place")
+			}
+			if tt.name != "password" {
+				if err := tt.signIn(t, f); err != nil {
+					t.Errorf("sign-in after the reset error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const (
+	methodPassword  = "password"
+	methodMFA       = "mfa"
+	methodOIDC      = "oidc:"
+	methodMagicLink = "magic_link"
+	methodWebAuthn  = "webauthn"
+)
+
+func (s *AuthService) ListAuthEvents(
+	ctx context.Context,
+	filter domain.AuthEventFilter,
+	cursor *uuid.UUID,
+	limit int,
+) ([]*domain.AuthEvent, *uuid.UUID, error) {
+	return s.eventRepo.List(ctx, filter, cursor, limit)
+}
+
+// recordEvent appends to the audit log. A failed write is logged rather than
+// returned so an audit outage does not lock everyone out.
+func (s *AuthService) recordEvent(ctx context.Context, event domain.AuthEvent) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Printf("auth: recording %s event: %v", event.Type, err)
+		return
+	}
+	event.ID = id
+	event.CreatedAt = time.Now()
+	if err := s.eventRepo.Record(ctx, &event); err != nil {
+		log.Printf("auth: recording %s event: %v", event.Type, err)
+	}
+}
+
+// recordOutcome records an event for a user action that either went through
+// or failed with err.
+func (s *AuthService) recordOutcome(
+	ctx context.Context,
+	eventType domain.AuthEventType,
+	userID uuid.UUID,
+	device domain.DeviceInfo,
+	err error,
+) {
+	event := domain.AuthEvent{
+		Type:      eventType,
+		UserID:    &userID,
+		IP:        device.IP,
+		UserAgent: device.UserAgent,
+	}
+	setOutcome(&event, err)
+	s.recordEvent(ctx, event)
+}
+
+func (s *AuthService) recordSignIn(
+	ctx context.Context,
+	method, email string,
+	user *domain.User,
+	device domain.DeviceInfo,
+	result *domain.SignInResult,
+	err error,
+) {
+	event := domain.AuthEvent{
+		Type:      domain.EventSignIn,
+		Method:    method,
+		Email:     email,
+		IP:        device.IP,
+		UserAgent: device.UserAgent,
+	}
+	if user != nil {
+		event.UserID = &user.ID
+		event.Email = user.Email
+	}
+	setOutcome(&event, err)
+	if err == nil && result != nil && result.MFAToken != "" {
+		event.Outcome = domain.OutcomeMFARequired
+	}
+	s.recordEvent(ctx, event)
+}
+
+// recordAdminAction appends a change an admin made to someone's account.
+// Unlike recordEvent it returns the error, so an admin action is never
+// reported as done without its audit entry.
+func (s *AuthService) recordAdminAction(ctx context.Context, event domain.AuthEvent) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	event.ID = id
+	event.Outcome = domain.OutcomeSuccess
+	event.CreatedAt = time.Now()
+	return s.eventRepo.Record(ctx, &event)
+}
+
+func adminEvent(eventType domain.AuthEventType, actorID, userID uuid.UUID, reason string) domain.AuthEvent {
+	return domain.AuthEvent{
+		Type:    eventType,
+		Reason:  reason,
+		UserID:  &userID,
+		ActorID: &actorID,
+	}
+}
+
+func setOutcome(event *domain.AuthEvent, err error) {
+	if err == nil {
+		event.Outcome = domain.OutcomeSuccess
+		return
+	}
+	event.Outcome = domain.OutcomeFailure
+	event.Reason = failureReason(err)
+}
+
+func failureReason(err error) string {
+	var lockout *domain.LockoutError
+	switch {
+	case errors.As(err, &lockout):
+		return "locked"
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrInvalidPassword):
+		return "invalid_credentials"
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		return "invalid_mfa_code"
+	case errors.Is(err, domain.ErrAccountDisabled):
+		return "account_disabled"
+	case errors.Is(err, domain.ErrPasswordResetNeeded):
+		return "password_reset_required"
+	case errors.Is(err, domain.ErrWeakPassword):
+		return "weak_password"
+	case errors.Is(err, domain.ErrOIDCEmailUnverified):
+		return "email_unverified"
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		return "token_reused"
+	case errors.Is(err, domain.ErrInvalidActionToken), errors.Is(err, domain.ErrInvalidOIDCState),
+		errors.Is(err, domain.ErrInvalidWebAuthnChallenge):
+		return "invalid_token"
+	case errors.Is(err, domain.ErrInvalidWebAuthnResponse), errors.Is(err, domain.ErrWebAuthnCredentialUnknown):
+		return "invalid_passkey"
+	case errors.Is(err, domain.ErrWebAuthnSignCount):
+		return "passkey_cloned"
+	default:
+		return "error"
+	}
+}
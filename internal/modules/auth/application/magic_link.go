@@ -0,0 +1,189 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/mail"
+)
+
+const (
+	// magicLinkCallbackPath is served by this API, which holds the nonce
+	// cookie, rather than by the frontend.
+	magicLinkCallbackPath = "/api/v1/auth/magic-link/callback"
+	magicLinkSendTimeout  = 30 * time.Second
+
+	defaultMagicLinkTTL         = 15 * time.Minute
+	defaultMagicLinkMaxRequests = 5
+	defaultMagicLinkWindow      = time.Hour
+)
+
+// RequestMagicLink mails a single-use sign-in link bound to the browser nonce.
+// Unknown and disabled accounts get no mail but count against the per-email
+// limit all the same, and the link is looked up and sent in the background,
+// so neither the outcome nor the response time reveals whether an account
+// exists.
+func (s *AuthService) RequestMagicLink(ctx context.Context, email, nonce string) error {
+	if err := s.throttleMagicLink(ctx, email); err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), magicLinkSendTimeout)
+		defer cancel()
+		if err := s.sendMagicLink(ctx, email, nonce); err != nil {
+			log.Printf("auth: sending magic link: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *AuthService) sendMagicLink(ctx context.Context, email, nonce string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	ttl, err := s.MagicLinkTTL()
+	if err != nil {
+		return err
+	}
+	token, plain, err := domain.NewActionToken(domain.PurposeMagicLink, user.ID, ttl, s.magicLinkKey(nonce))
+	if err != nil {
+		return err
+	}
+	if err := s.actionTokenStore.Save(ctx, token); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf(
+			"Sign in by opening the link below in the browser you requested it from. It expires in %s.\n"+
+				"If you did not ask to sign in you can ignore this email.\n\n%s\n",
+			ttl, s.magicLinkURL(plain),
+		),
+	})
+}
+
+func (s *AuthService) magicLinkURL(token string) string {
+	return strings.TrimRight(s.oidcCfg.RedirectBaseURL, "/") + magicLinkCallbackPath + "?token=" + url.QueryEscape(token)
+}
+
+// CompleteMagicLink signs in with a mailed link. Opening the link proves the
+// user controls the address, so an unverified email is verified on the way.
+func (s *AuthService) CompleteMagicLink(
+	ctx context.Context,
+	token, nonce string,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, error) {
+	result, user, err := s.completeMagicLink(ctx, token, nonce, device)
+	s.recordSignIn(ctx, methodMagicLink, "", user, device, result, err)
+	return result, err
+}
+
+func (s *AuthService) completeMagicLink(
+	ctx context.Context,
+	token, nonce string,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, *domain.User, error) {
+	if nonce == "" {
+		return nil, nil, domain.ErrInvalidActionToken
+	}
+	hash, err := domain.VerifyActionToken(domain.PurposeMagicLink, token, s.magicLinkKey(nonce))
+	if err != nil {
+		return nil, nil, err
+	}
+	userID, err := s.actionTokenStore.Consume(ctx, domain.PurposeMagicLink, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, domain.ErrInvalidActionToken
+		}
+		return nil, nil, err
+	}
+	if user.IsDisabled() {
+		return nil, user, domain.ErrAccountDisabled
+	}
+	if !user.IsVerified() {
+		user.MarkVerified()
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, user, err
+		}
+	}
+
+	if user.HasMFA() {
+		mfaToken, err := s.issueMFAToken(ctx, user)
+		if err != nil {
+			return nil, user, err
+		}
+		return &domain.SignInResult{MFAToken: mfaToken}, user, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, device, false)
+	if err != nil {
+		return nil, user, err
+	}
+	return &domain.SignInResult{Tokens: tokens}, user, nil
+}
+
+func (s *AuthService) MagicLinkTTL() (time.Duration, error) {
+	if s.authCfg.MagicLinkTokenTTL == "" {
+		return defaultMagicLinkTTL, nil
+	}
+	return time.ParseDuration(s.authCfg.MagicLinkTokenTTL)
+}
+
+func (s *AuthService) magicLinkKey(nonce string) []byte {
+	return domain.MagicLinkKey([]byte(s.authCfg.TokenSecret), nonce)
+}
+
+// throttleMagicLink locks an email for the rest of the window once it has
+// asked for more than MagicLinkMaxRequests links.
+func (s *AuthService) throttleMagicLink(ctx context.Context, email string) error {
+	key := domain.MagicLinkAttemptKey(email)
+	if err := s.checkSignInLock(ctx, key); err != nil {
+		return err
+	}
+
+	window := defaultMagicLinkWindow
+	if s.authCfg.MagicLinkRequestWindow != "" {
+		var err error
+		if window, err = time.ParseDuration(s.authCfg.MagicLinkRequestWindow); err != nil {
+			return err
+		}
+	}
+	limit := s.authCfg.MagicLinkMaxRequests
+	if limit <= 0 {
+		limit = defaultMagicLinkMaxRequests
+	}
+
+	requests, err := s.attemptStore.RegisterFailure(ctx, key, window)
+	if err != nil {
+		return err
+	}
+	if requests > limit {
+		if err := s.attemptStore.Lock(ctx, key, window); err != nil {
+			return err
+		}
+		return &domain.LockoutError{RetryAfter: window}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package application
+
+import (
+	"testing"
+
+	"bobshop/internal/platform/config"
+)
+
+func TestMagicLinkURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		token   string
+		want    string
+	}{
+		{
+			name:    "points at the API callback",
+			baseURL: "https://api.example.com",
+			token:   "abc",
+			want:    "https://api.example.com/api/v1/auth/magic-link/callback?token=abc",
+		},
+		{
+			name:    "trailing slash",
+			baseURL: "https://api.example.com/",
+			token:   "abc",
+			want:    "https://api.example.com/api/v1/auth/magic-link/callback?token=abc",
+		},
+		{
+			name:    "token escaped",
+			baseURL: "https://api.example.com",
+			token:   "a+b/c=",
+			want:    "https://api.example.com/api/v1/auth/magic-link/callback?token=a%2Bb%2Fc%3D",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AuthService{oidcCfg: &config.OIDCConfig{RedirectBaseURL: tt.baseURL}}
+			if got := s.magicLinkURL(tt.token); got != tt.want {
+				t.Errorf("magicLinkURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/pkg/auth"
+)
+
+const mfaAttemptKeyPrefix = "mfa:"
+
+type TOTPSetup struct {
+	Secret string
+	URI    string
+}
+
+func (s *AuthService) SetupTOTP(ctx context.Context, userID uuid.UUID) (*TOTPSetup, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := user.BeginTOTPSetup()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return &TOTPSetup{
+		Secret: secret,
+		URI:    auth.TOTPURI(s.authCfg.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication and returns the recovery
+// codes. Existing sessions keep working; the next sign-in asks for a code.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string, device domain.DeviceInfo) ([]string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := user.ConfirmTOTP(code, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.recordOutcome(ctx, domain.EventMFAEnabled, user.ID, device, nil)
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off. The user must present a
+// current TOTP or recovery code, so a hijacked session alone cannot remove it.
+func (s *AuthService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string, device domain.DeviceInfo) (err error) {
+	defer func() {
+		s.recordOutcome(ctx, domain.EventMFADisabled, userID, device, err)
+	}()
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkAccountSecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+	if err := user.DisableMFA(); err != nil {
+		return err
+	}
+	return s.userRepo.Update(ctx, user)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a
+// current TOTP or recovery code, and returns the new codes.
+func (s *AuthService) RegenerateRecoveryCodes(
+	ctx context.Context,
+	userID uuid.UUID,
+	code string,
+	device domain.DeviceInfo,
+) (codes []string, err error) {
+	defer func() {
+		s.recordOutcome(ctx, domain.EventRecoveryCodesReset, userID, device, err)
+	}()
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccountSecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	}
+	if codes, err = user.RegenerateRecoveryCodes([]byte(s.authCfg.TokenSecret)); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkAccountSecondFactor guards changes to a signed-in user's two-factor
+// settings. Wrong codes count towards the same lock as VerifyMFA.
+func (s *AuthService) checkAccountSecondFactor(ctx context.Context, user *domain.User, code string) error {
+	if !user.HasMFA() {
+		return domain.ErrMFANotEnabled
+	}
+	key := mfaAttemptKey(user.ID)
+	if err := s.checkSignInLock(ctx, key); err != nil {
+		return err
+	}
+	if user.VerifySecondFactor(code, []byte(s.authCfg.TokenSecret)) {
+		return s.attemptStore.Reset(ctx, key)
+	}
+	if _, err := s.registerFailedMFA(ctx, user.ID); err != nil {
+		return err
+	}
+	return domain.ErrInvalidMFACode
+}
+
+// VerifyMFA exchanges the MFA token from SignIn and a TOTP or recovery code for
+// a session. Wrong codes are counted per user rather than per MFA token, so
+// signing in again with the password does not buy more guesses; at the failed
+// attempt limit the second factor locks like a failed sign-in and the MFA
+// token is burned.
+func (s *AuthService) VerifyMFA(
+	ctx context.Context,
+	mfaToken, code string,
+	device domain.DeviceInfo,
+) (*domain.AuthTokens, error) {
+	hash, err := domain.VerifyActionToken(domain.PurposeMFAPending, mfaToken, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return nil, err
+	}
+	userID, err := s.actionTokenStore.Peek(ctx, domain.PurposeMFAPending, hash)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidActionToken
+		}
+		return nil, err
+	}
+
+	tokens, err := s.completeMFA(ctx, user, hash, code, device)
+	s.recordSignIn(ctx, methodMFA, user.Email, user, device, nil, err)
+	return tokens, err
+}
+
+func (s *AuthService) completeMFA(
+	ctx context.Context,
+	user *domain.User,
+	hash, code string,
+	device domain.DeviceInfo,
+) (*domain.AuthTokens, error) {
+	key := mfaAttemptKey(user.ID)
+	if err := s.checkSignInLock(ctx, key); err != nil {
+		return nil, err
+	}
+	if !user.VerifySecondFactor(code, []byte(s.authCfg.TokenSecret)) {
+		locked, err := s.registerFailedMFA(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			_, err := s.actionTokenStore.Consume(ctx, domain.PurposeMFAPending, hash)
+			if err != nil && !errors.Is(err, domain.ErrInvalidActionToken) {
+				return nil, err
+			}
+		}
+		return nil, domain.ErrInvalidMFACode
+	}
+	if _, err := s.actionTokenStore.Consume(ctx, domain.PurposeMFAPending, hash); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.attemptStore.Reset(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, device, true)
+}
+
+func (s *AuthService) issueMFAToken(ctx context.Context, user *domain.User) (string, error) {
+	if err := checkCanSignIn(user); err != nil {
+		return "", err
+	}
+	return s.issueActionToken(ctx, domain.PurposeMFAPending, user.ID, s.authCfg.MFATokenTTL)
+}
+
+func mfaAttemptKey(userID uuid.UUID) string {
+	return mfaAttemptKeyPrefix + userID.String()
+}
+
+// registerFailedMFA counts a wrong second factor code against the user and
+// reports whether that locked their second factor.
+func (s *AuthService) registerFailedMFA(ctx context.Context, userID uuid.UUID) (bool, error) {
+	key := mfaAttemptKey(userID)
+	failures, err := s.attemptStore.RegisterFailure(ctx, key, s.authCfg.FailedAttemptWindow)
+	if err != nil {
+		return false, err
+	}
+	d := domain.LockoutDuration(failures, s.authCfg.MaxFailedAttempts, s.authCfg.LockoutDuration, s.authCfg.MaxLockoutDuration)
+	if d == 0 {
+		return false, nil
+	}
+	return true, s.attemptStore.Lock(ctx, key, d)
+}
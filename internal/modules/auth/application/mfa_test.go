@@ -0,0 +1,133 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/pkg/auth"
+)
+
+type memAttempts struct {
+	failures map[string]int64
+	locks    map[string]time.Duration
+}
+
+func newMemAttempts() *memAttempts {
+	return &memAttempts{failures: map[string]int64{}, locks: map[string]time.Duration{}}
+}
+
+func (m *memAttempts) RegisterFailure(_ context.Context, key string, _ time.Duration) (int64, error) {
+	m.failures[key]++
+	return m.failures[key], nil
+}
+
+func (m *memAttempts) Lock(_ context.Context, key string, d time.Duration) error {
+	m.locks[key] = d
+	return nil
+}
+
+func (m *memAttempts) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	return m.locks[key], nil
+}
+
+func (m *memAttempts) Reset(_ context.Context, key string) error {
+	delete(m.failures, key)
+	delete(m.locks, key)
+	return nil
+}
+
+// newMFAFixture returns an account with TOTP enabled and its recovery codes.
+func newMFAFixture(t *testing.T) (*accountFixture, []string) {
+	t.Helper()
+	f := newAccountFixture(t)
+	f.service.attemptStore = newMemAttempts()
+	f.service.authCfg.MaxFailedAttempts = 3
+	f.service.authCfg.FailedAttemptWindow = time.Hour
+	f.service.authCfg.LockoutDuration = time.Minute
+	f.service.authCfg.MaxLockoutDuration = time.Hour
+	f.service.authCfg.MFATokenTTL = time.Minute
+
+	secret, err := f.user.BeginTOTPSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := f.user.ConfirmTOTP(code, []byte(f.service.authCfg.TokenSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, codes
+}
+
+// passwordStep signs in with the password and returns the MFA token.
+func (f *accountFixture) passwordStep(t *testing.T) string {
+	t.Helper()
+	result, err := f.service.SignIn(context.Background(), f.user.Email, testPassword, domain.DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MFAToken == "" {
+		t.Fatal("SignIn() did not ask for a second factor")
+	}
+	return result.MFAToken
+}
+
+func TestVerifyMFALockout(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		// wrong holds the number of wrong codes sent with each MFA token,
+		// one password sign-in per entry.
+		wrong   []int
+		wantErr error
+	}{
+		{name: "correct code"},
+		{name: "below the limit", wrong: []int{2}},
+		{name: "at the limit", wrong: []int{3}, wantErr: domain.ErrAccountLocked},
+		{name: "across sign-ins", wrong: []int{2, 1}, wantErr: domain.ErrAccountLocked},
+		{name: "across many sign-ins", wrong: []int{1, 1, 1}, wantErr: domain.ErrAccountLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, codes := newMFAFixture(t)
+			for _, n := range tt.wrong {
+				mfaToken := f.passwordStep(t)
+				for range n {
+					_, err := f.service.VerifyMFA(ctx, mfaToken, "000000", domain.DeviceInfo{})
+					if !errors.Is(err, domain.ErrInvalidMFACode) {
+						t.Fatalf("VerifyMFA() with a wrong code error = %v, want %v", err, domain.ErrInvalidMFACode)
+					}
+				}
+			}
+
+			// Once locked, not even a fresh MFA token and a correct code get in.
+			tokens, err := f.service.VerifyMFA(ctx, f.passwordStep(t), codes[0], domain.DeviceInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyMFA() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && tokens == nil {
+				t.Fatal("VerifyMFA() returned no tokens")
+			}
+		})
+	}
+}
+
+func TestAccountSecondFactorSharesLock(t *testing.T) {
+	ctx := context.Background()
+	f, codes := newMFAFixture(t)
+	for range 3 {
+		_, err := f.service.RegenerateRecoveryCodes(ctx, f.user.ID, "000000", domain.DeviceInfo{})
+		if !errors.Is(err, domain.ErrInvalidMFACode) {
+			t.Fatalf("RegenerateRecoveryCodes() error = %v, want %v", err, domain.ErrInvalidMFACode)
+		}
+	}
+	if _, err := f.service.VerifyMFA(ctx, f.passwordStep(t), codes[0], domain.DeviceInfo{}); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Errorf("VerifyMFA() error = %v, want %v", err, domain.ErrAccountLocked)
+	}
+}
@@ -0,0 +1,129 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const defaultOIDCStateTTL = 10 * time.Minute
+
+// StartOIDC prepares a login with an external provider and returns the URL to
+// send the browser to along with the state the callback must echo back.
+func (s *AuthService) StartOIDC(ctx context.Context, provider string) (string, *domain.OIDCAuthRequest, error) {
+	req, err := domain.NewOIDCAuthRequest(provider)
+	if err != nil {
+		return "", nil, err
+	}
+	authURL, err := s.oidcClient.AuthCodeURL(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+	ttl, err := s.OIDCStateTTL()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.oidcStateStore.Save(ctx, req, ttl); err != nil {
+		return "", nil, err
+	}
+	return authURL, req, nil
+}
+
+// CompleteOIDC finishes a provider login. Known identities sign straight in;
+// otherwise the identity is linked to the account with the same email, or a
+// new account is created, but only when the provider verified that email.
+func (s *AuthService) CompleteOIDC(
+	ctx context.Context,
+	provider, state, code string,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, error) {
+	result, user, err := s.completeOIDC(ctx, provider, state, code, device)
+	s.recordSignIn(ctx, methodOIDC+provider, "", user, device, result, err)
+	return result, err
+}
+
+func (s *AuthService) completeOIDC(
+	ctx context.Context,
+	provider, state, code string,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, *domain.User, error) {
+	req, err := s.oidcStateStore.Consume(ctx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Provider != provider {
+		return nil, nil, domain.ErrInvalidOIDCState
+	}
+
+	identity, err := s.oidcClient.Exchange(ctx, req, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.FindByIdentity(ctx, identity.Provider, identity.Subject)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = s.linkOIDCIdentity(ctx, identity)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.HasMFA() {
+		mfaToken, err := s.issueMFAToken(ctx, user)
+		if err != nil {
+			return nil, user, err
+		}
+		return &domain.SignInResult{MFAToken: mfaToken}, user, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, device, false)
+	if err != nil {
+		return nil, user, err
+	}
+	return &domain.SignInResult{Tokens: tokens}, user, nil
+}
+
+func (s *AuthService) OIDCStateTTL() (time.Duration, error) {
+	if s.oidcCfg.StateTTL == "" {
+		return defaultOIDCStateTTL, nil
+	}
+	return time.ParseDuration(s.oidcCfg.StateTTL)
+}
+
+func (s *AuthService) linkOIDCIdentity(ctx context.Context, identity *domain.ExternalIdentity) (*domain.User, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, domain.ErrOIDCEmailUnverified
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, identity.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = domain.NewExternalUser(identity)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Anyone could have registered an unverified account for this email, so
+	// its password and sessions are dropped before the real owner takes over.
+	if !user.IsVerified() {
+		user.PasswordHash = ""
+		user.MarkVerified()
+		if err := s.sessionStore.RevokeAll(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	user.LinkIdentity(identity)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
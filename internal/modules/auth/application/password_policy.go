@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/pkg/auth"
+)
+
+const (
+	defaultPasswordMinLength = 8
+	defaultPasswordMaxLength = 128
+	// bcrypt only looks at the first 72 bytes of a password.
+	bcryptMaxLength = 72
+)
+
+// checkPassword applies the configured policy and the breached password
+// corpus, returning a *domain.PasswordPolicyError listing every failed rule.
+func (s *AuthService) checkPassword(ctx context.Context, password, email string) error {
+	violations := s.passwordPolicy().Check(password, email)
+
+	breached, err := s.breachChecker.IsBreached(ctx, password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		violations = append(violations, domain.PasswordViolation{
+			Rule:    domain.PasswordRuleBreached,
+			Message: "appears in a known data breach; choose a different password",
+		})
+	}
+
+	if len(violations) > 0 {
+		return &domain.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *AuthService) passwordPolicy() domain.PasswordPolicy {
+	cfg := s.authCfg.PasswordPolicy
+	policy := domain.PasswordPolicy{
+		MinLength:     cfg.MinLength,
+		MaxLength:     cfg.MaxLength,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+		DisallowEmail: cfg.DisallowEmail,
+	}
+	if policy.MinLength == 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+	if policy.MaxLength == 0 {
+		policy.MaxLength = defaultPasswordMaxLength
+	}
+	if s.authCfg.PasswordHashing.Algorithm == auth.AlgorithmBcrypt {
+		policy.MaxBytes = bcryptMaxLength
+	}
+	return policy
+}
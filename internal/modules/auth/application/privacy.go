@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+// UserDataSubject covers the account itself and its sessions. Secrets such as
+// password hashes and TOTP keys are left out of exports.
+type UserDataSubject struct {
+	userRepo     domain.AuthRepository
+	sessionStore domain.SessionStore
+	attemptStore domain.LoginAttemptStore
+}
+
+func NewUserDataSubject(
+	userRepo domain.AuthRepository,
+	sessionStore domain.SessionStore,
+	attemptStore domain.LoginAttemptStore,
+) *UserDataSubject {
+	return &UserDataSubject{
+		userRepo:     userRepo,
+		sessionStore: sessionStore,
+		attemptStore: attemptStore,
+	}
+}
+
+type accountExport struct {
+	ID           uuid.UUID         `json:"id"`
+	Email        string            `json:"email"`
+	Role         string            `json:"role"`
+	VerifiedAt   *time.Time        `json:"verified_at"`
+	MFAEnabledAt *time.Time        `json:"mfa_enabled_at"`
+	Profile      domain.Profile    `json:"profile"`
+	Identities   []domain.Identity `json:"identities"`
+	DisabledAt   *time.Time        `json:"disabled_at"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Sessions     []*domain.Session `json:"sessions"`
+}
+
+func (u *UserDataSubject) Name() string {
+	return "account"
+}
+
+func (u *UserDataSubject) ExportUserData(ctx context.Context, userID uuid.UUID) (any, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.sessionStore.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &accountExport{
+		ID:           user.ID,
+		Email:        user.Email,
+		Role:         user.Role.String(),
+		VerifiedAt:   user.VerifiedAt,
+		MFAEnabledAt: user.MFAEnabledAt,
+		Profile:      user.Profile,
+		Identities:   user.Identities,
+		DisabledAt:   user.DisabledAt,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		Sessions:     sessions,
+	}, nil
+}
+
+// PasskeyDataSubject covers the user's registered passkeys. Exports list them
+// without their public keys.
+type PasskeyDataSubject struct {
+	userRepo domain.AuthRepository
+}
+
+func NewPasskeyDataSubject(userRepo domain.AuthRepository) *PasskeyDataSubject {
+	return &PasskeyDataSubject{userRepo: userRepo}
+}
+
+func (p *PasskeyDataSubject) Name() string {
+	return "passkeys"
+}
+
+func (p *PasskeyDataSubject) ExportUserData(ctx context.Context, userID uuid.UUID) (any, error) {
+	user, err := p.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.WebAuthnCredentials == nil {
+		return []domain.WebAuthnCredential{}, nil
+	}
+	return user.WebAuthnCredentials, nil
+}
+
+func (p *PasskeyDataSubject) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	user, err := p.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(user.WebAuthnCredentials) == 0 {
+		return nil
+	}
+	user.WebAuthnCredentials = nil
+	user.UpdatedAt = time.Now()
+	return p.userRepo.Update(ctx, user)
+}
+
+// AuthEventDataSubject covers the user's entries in the auth event log. Erasure
+// anonymises them rather than deleting them, so the security record of what
+// happened to the account survives it.
+type AuthEventDataSubject struct {
+	userRepo  domain.AuthRepository
+	eventRepo domain.AuthEventRepository
+}
+
+func NewAuthEventDataSubject(userRepo domain.AuthRepository, eventRepo domain.AuthEventRepository) *AuthEventDataSubject {
+	return &AuthEventDataSubject{userRepo: userRepo, eventRepo: eventRepo}
+}
+
+func (a *AuthEventDataSubject) Name() string {
+	return "auth_events"
+}
+
+func (a *AuthEventDataSubject) ExportUserData(ctx context.Context, userID uuid.UUID) (any, error) {
+	return a.eventRepo.ListByUser(ctx, userID)
+}
+
+// EraseUserData looks the email up while the account still exists; on a retry
+// after the account is gone it anonymises by user ID alone.
+func (a *AuthEventDataSubject) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	var email string
+	user, err := a.userRepo.FindByID(ctx, userID)
+	switch {
+	case err == nil:
+		email = user.Email
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return err
+	}
+	return a.eventRepo.Anonymize(ctx, userID, email)
+}
+
+func (u *UserDataSubject) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := u.sessionStore.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	if err := u.attemptStore.Reset(ctx, domain.AccountAttemptKey(user.Email)); err != nil {
+		return err
+	}
+	return u.userRepo.Delete(ctx, userID)
+}
@@ -0,0 +1,114 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+type stubUserRepo struct {
+	domain.AuthRepository
+	user    *domain.User
+	err     error
+	updated bool
+}
+
+func (r *stubUserRepo) FindByID(context.Context, uuid.UUID) (*domain.User, error) {
+	return r.user, r.err
+}
+
+func (r *stubUserRepo) Update(context.Context, *domain.User) error {
+	r.updated = true
+	return nil
+}
+
+type stubEventRepo struct {
+	domain.AuthEventRepository
+	anonymized bool
+	email      string
+}
+
+func (r *stubEventRepo) Anonymize(_ context.Context, _ uuid.UUID, email string) error {
+	r.anonymized = true
+	r.email = email
+	return nil
+}
+
+func TestAuthEventDataSubjectErase(t *testing.T) {
+	errStore := errors.New("store down")
+	tests := []struct {
+		name           string
+		user           *domain.User
+		findErr        error
+		wantErr        error
+		wantAnonymized bool
+		wantEmail      string
+	}{
+		{
+			name:           "account still exists",
+			user:           &domain.User{Email: "ada@example.com"},
+			wantAnonymized: true,
+			wantEmail:      "ada@example.com",
+		},
+		{
+			name:           "retry after the account is gone",
+			findErr:        domain.ErrUserNotFound,
+			wantAnonymized: true,
+		},
+		{
+			name:    "lookup fails",
+			findErr: errStore,
+			wantErr: errStore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &stubEventRepo{}
+			subject := NewAuthEventDataSubject(&stubUserRepo{user: tt.user, err: tt.findErr}, events)
+
+			err := subject.EraseUserData(context.Background(), uuid.New())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EraseUserData() error = %v, want %v", err, tt.wantErr)
+			}
+			if events.anonymized != tt.wantAnonymized || events.email != tt.wantEmail {
+				t.Errorf("Anonymize called = %v with %q, want %v with %q",
+					events.anonymized, events.email, tt.wantAnonymized, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestPasskeyDataSubjectErase(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        *domain.User
+		findErr     error
+		wantUpdated bool
+	}{
+		{
+			name:        "removes passkeys",
+			user:        &domain.User{WebAuthnCredentials: []domain.WebAuthnCredential{{ID: []byte{1}}}},
+			wantUpdated: true,
+		},
+		{name: "no passkeys", user: &domain.User{}},
+		{name: "account already gone", findErr: domain.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &stubUserRepo{user: tt.user, err: tt.findErr}
+			if err := NewPasskeyDataSubject(users).EraseUserData(context.Background(), uuid.New()); err != nil {
+				t.Fatalf("EraseUserData() error = %v", err)
+			}
+			if users.updated != tt.wantUpdated {
+				t.Errorf("user updated = %v, want %v", users.updated, tt.wantUpdated)
+			}
+			if tt.user != nil && len(tt.user.WebAuthnCredentials) != 0 {
+				t.Errorf("passkeys left: %d", len(tt.user.WebAuthnCredentials))
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/mail"
+)
+
+const confirmEmailChangePath = "/confirm-email"
+
+func (s *AuthService) GetProfile(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	return s.userRepo.FindByID(ctx, userID)
+}
+
+func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, update domain.ProfileUpdate) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.UpdateProfile(update)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RequestEmailChange mails a confirmation link to the new address. The current
+// address stays in use until the link is opened. Accounts without a password,
+// created through an identity provider, skip the password check.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail, password string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash != "" && !user.CheckPassword(password) {
+		return domain.ErrInvalidPassword
+	}
+	newEmail = domain.NormalizeEmail(newEmail)
+	if newEmail == domain.NormalizeEmail(user.Email) {
+		return domain.ErrEmailUnchanged
+	}
+	if err := s.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	ttl := s.authCfg.VerificationTokenTTL
+	token, err := s.issueActionToken(ctx, domain.PurposeEmailChange, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+	hash, err := domain.VerifyActionToken(domain.PurposeEmailChange, token, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return err
+	}
+	if err := user.RequestEmailChange(newEmail, hash); err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// The request is saved; if the mail is lost the user simply asks again.
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Confirm that this is your new email address by opening the link below. It expires in %s.\n\n%s\n",
+			ttl, s.buildLink(confirmEmailChangePath, token),
+		),
+	})
+	if err != nil {
+		log.Printf("auth: sending email change confirmation for %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the new address and lets the old
+// address know, so an unexpected change does not go unnoticed.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string, device domain.DeviceInfo) error {
+	hash, err := domain.VerifyActionToken(domain.PurposeEmailChange, token, []byte(s.authCfg.TokenSecret))
+	if err != nil {
+		return err
+	}
+	userID, err := s.actionTokenStore.Consume(ctx, domain.PurposeEmailChange, hash)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidActionToken
+		}
+		return err
+	}
+	if user.EmailChange != nil {
+		if err := s.ensureEmailAvailable(ctx, user.EmailChange.Email); err != nil {
+			return err
+		}
+	}
+	previous, err := user.ConfirmEmailChange(hash)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, domain.AuthEvent{
+		Type:      domain.EventEmailChange,
+		Outcome:   domain.OutcomeSuccess,
+		Reason:    "changed from " + previous,
+		UserID:    &user.ID,
+		Email:     user.Email,
+		IP:        device.IP,
+		UserAgent: device.UserAgent,
+	})
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      previous,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf(
+			"The email address on your account was changed to %s.\n"+
+				"If you did not make this change, reset your password and contact support.\n",
+			user.Email,
+		),
+	})
+	if err != nil {
+		log.Printf("auth: notifying %s of the email change: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *AuthService) ensureEmailAvailable(ctx context.Context, email string) error {
+	existing, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	if existing != nil {
+		return domain.ErrUserAlreadyExists
+	}
+	return nil
+}
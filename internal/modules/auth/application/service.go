@@ -2,48 +2,452 @@ package application
 
 import (
 	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
 
 	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/mail"
 	"bobshop/internal/platform/security"
+	"bobshop/pkg/auth"
 )
 
 type AuthService struct {
-	userRepo  domain.AuthRepository
-	tokenizer security.Tokenizer
+	userRepo         domain.AuthRepository
+	eventRepo        domain.AuthEventRepository
+	tokenStore       domain.RefreshTokenStore
+	sessionStore     domain.SessionStore
+	actionTokenStore domain.ActionTokenStore
+	attemptStore     domain.LoginAttemptStore
+	oidcClient       domain.OIDCClient
+	oidcStateStore   domain.OIDCStateStore
+	webAuthnStore    domain.WebAuthnChallengeStore
+	breachChecker    domain.BreachedPasswordChecker
+	tokenizer        security.Tokenizer
+	mailer           mail.Mailer
+	cfg              *config.JWTConfig
+	authCfg          *config.AuthConfig
+	oidcCfg          *config.OIDCConfig
 }
 
-func NewAuthService(userRepo domain.AuthRepository, tokenizer security.Tokenizer) *AuthService {
+func NewAuthService(
+	userRepo domain.AuthRepository,
+	eventRepo domain.AuthEventRepository,
+	tokenStore domain.RefreshTokenStore,
+	sessionStore domain.SessionStore,
+	actionTokenStore domain.ActionTokenStore,
+	attemptStore domain.LoginAttemptStore,
+	oidcClient domain.OIDCClient,
+	oidcStateStore domain.OIDCStateStore,
+	webAuthnStore domain.WebAuthnChallengeStore,
+	breachChecker domain.BreachedPasswordChecker,
+	tokenizer security.Tokenizer,
+	mailer mail.Mailer,
+	cfg *config.JWTConfig,
+	authCfg *config.AuthConfig,
+	oidcCfg *config.OIDCConfig,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		tokenizer: tokenizer,
+		userRepo:         userRepo,
+		eventRepo:        eventRepo,
+		tokenStore:       tokenStore,
+		sessionStore:     sessionStore,
+		actionTokenStore: actionTokenStore,
+		attemptStore:     attemptStore,
+		oidcClient:       oidcClient,
+		oidcStateStore:   oidcStateStore,
+		webAuthnStore:    webAuthnStore,
+		breachChecker:    breachChecker,
+		tokenizer:        tokenizer,
+		mailer:           mailer,
+		cfg:              cfg,
+		authCfg:          authCfg,
+		oidcCfg:          oidcCfg,
 	}
 }
 
 func (s *AuthService) SignUp(ctx context.Context, email, password string) error {
 	existingUser, err := s.userRepo.FindByEmail(ctx, email)
-	if err != nil {
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
 		return err
 	}
 	if existingUser != nil {
 		return domain.ErrUserAlreadyExists
 	}
+	if err := s.checkPassword(ctx, password, email); err != nil {
+		return err
+	}
 
 	newUser, err := domain.NewUser(email, password)
 	if err != nil {
 		return err
 	}
 
-	return s.userRepo.Create(ctx, newUser)
+	if err := s.userRepo.Create(ctx, newUser); err != nil {
+		return err
+	}
+	// The account exists either way; a lost mail is fixed by resending it.
+	if err := s.sendVerificationEmail(ctx, newUser); err != nil {
+		log.Printf("auth: sending verification email to %s: %v", newUser.ID, err)
+	}
+	return nil
+}
+
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.consumeActionToken(ctx, domain.PurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidActionToken
+		}
+		return err
+	}
+	if user.IsVerified() {
+		return nil
+	}
+	user.MarkVerified()
+	return s.userRepo.Update(ctx, user)
+}
+
+// ResendVerification succeeds silently for unknown or already verified emails
+// so the endpoint cannot be used to probe which addresses are registered.
+func (s *AuthService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsVerified() {
+		return nil
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ForgotPassword mails a reset link. Like ResendVerification it reports
+// success for unknown emails, and a mail failure is only logged so the reply
+// does not tell registered addresses apart either.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.sendPasswordResetEmail(ctx, user); err != nil {
+		log.Printf("auth: sending password reset email to %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password and signs the user out of every session.
+// The token is only used up once the new password passes the policy, so the
+// user can retry with a stronger one.
+func (s *AuthService) ResetPassword(ctx context.Context, token, password string, device domain.DeviceInfo) error {
+	userID, err := s.peekActionToken(ctx, domain.PurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidActionToken
+		}
+		return err
+	}
+	err = s.resetPassword(ctx, user, token, password)
+	s.recordOutcome(ctx, domain.EventPasswordReset, user.ID, device, err)
+	return err
+}
+
+func (s *AuthService) resetPassword(ctx context.Context, user *domain.User, token, password string) error {
+	if err := s.checkPassword(ctx, password, user.Email); err != nil {
+		return err
+	}
+	if _, err := s.consumeActionToken(ctx, domain.PurposePasswordReset, token); err != nil {
+		return err
+	}
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := s.actionTokenStore.RevokeAll(ctx, domain.PurposePasswordReset, user.ID); err != nil {
+		return err
+	}
+	return s.sessionStore.RevokeAll(ctx, user.ID)
+}
+
+// ChangePassword requires the current password and signs out every session
+// except the one making the change.
+func (s *AuthService) ChangePassword(
+	ctx context.Context,
+	userID, currentSessionID uuid.UUID,
+	currentPassword, newPassword string,
+	device domain.DeviceInfo,
+) (err error) {
+	defer func() {
+		s.recordOutcome(ctx, domain.EventPasswordChange, userID, device, err)
+	}()
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.CheckPassword(currentPassword) {
+		return domain.ErrInvalidPassword
+	}
+	if err := s.checkPassword(ctx, newPassword, user.Email); err != nil {
+		return err
+	}
+	if err := user.SetPassword(newPassword); err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return s.revokeOtherSessions(ctx, userID, currentSessionID)
+}
+
+// SignIn reports unknown emails and wrong passwords alike as
+// ErrInvalidCredentials. Repeated failures lock the account or IP for an
+// exponentially growing period. Users with two-factor authentication get an
+// MFA token instead of a session.
+func (s *AuthService) SignIn(ctx context.Context, email, password string, device domain.DeviceInfo) (*domain.SignInResult, error) {
+	result, user, err := s.signIn(ctx, email, password, device)
+	s.recordSignIn(ctx, methodPassword, email, user, device, result, err)
+	return result, err
 }
 
-func (s *AuthService) SignIn(ctx context.Context, email, password string) (string, error) {
+// signIn also returns the user once the email is known, for the audit log.
+func (s *AuthService) signIn(
+	ctx context.Context,
+	email, password string,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, *domain.User, error) {
+	accountKey := domain.AccountAttemptKey(email)
+	if err := s.checkSignInLock(ctx, accountKey, domain.IPAttemptKey(device.IP)); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, err
+	}
+	if user == nil {
+		auth.ComparePassword(dummyPasswordHash(), password)
+	}
+	if user == nil || !user.CheckPassword(password) {
+		if err := s.registerFailedSignIn(ctx, email, device.IP); err != nil {
+			return nil, user, err
+		}
+		return nil, user, domain.ErrInvalidCredentials
+	}
+	if err := s.attemptStore.Reset(ctx, accountKey); err != nil {
+		return nil, user, err
+	}
+	if err := checkCanSignIn(user); err != nil {
+		return nil, user, err
+	}
+	if err := s.rehashPassword(ctx, user, password); err != nil {
+		return nil, user, err
+	}
+
+	if user.HasMFA() {
+		mfaToken, err := s.issueMFAToken(ctx, user)
+		if err != nil {
+			return nil, user, err
+		}
+		return &domain.SignInResult{MFAToken: mfaToken}, user, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, device, false)
+	if err != nil {
+		return nil, user, err
+	}
+	return &domain.SignInResult{Tokens: tokens}, user, nil
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already rotated
+// means it leaked, so its session is revoked and the caller must sign in again.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, device domain.DeviceInfo) (*domain.AuthTokens, error) {
+	token, err := s.tokenStore.Consume(ctx, domain.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			s.recordOutcome(ctx, domain.EventTokenReuse, token.UserID, device, err)
+			if revokeErr := s.revokeSession(ctx, token.UserID, token.SessionID); revokeErr != nil {
+				return nil, revokeErr
+			}
+		}
+		return nil, err
+	}
+
+	ttl := s.cfg.RefreshExpirationHours
+	if err := s.sessionStore.Extend(ctx, token.SessionID, ttl); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	session, err := s.sessionStore.Get(ctx, token.SessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// Reload the user so role and verification changes reach the new token.
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if checkCanSignIn(user) != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	return s.issueTokens(ctx, session, user, ttl)
+}
+
+func (s *AuthService) SignOut(ctx context.Context, refreshToken string, device domain.DeviceInfo) error {
+	if refreshToken == "" {
+		return nil
+	}
+	token, err := s.tokenStore.Consume(ctx, domain.HashRefreshToken(refreshToken))
+	if err != nil && !errors.Is(err, domain.ErrRefreshTokenReused) {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+	if err := s.revokeSession(ctx, token.UserID, token.SessionID); err != nil {
+		return err
+	}
+	s.recordOutcome(ctx, domain.EventSignOut, token.UserID, device, nil)
+	return nil
+}
+
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	return s.sessionStore.ListByUser(ctx, userID)
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.sessionStore.Revoke(ctx, userID, sessionID)
+}
+
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return s.sessionStore.RevokeAll(ctx, userID)
+}
+
+// rehashPassword migrates an outdated hash to the configured algorithm while
+// the plain password is at hand, so users move over without a reset.
+func (s *AuthService) rehashPassword(ctx context.Context, user *domain.User, password string) error {
+	if !user.PasswordNeedsRehash() {
+		return nil
+	}
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *AuthService) revokeOtherSessions(ctx context.Context, userID, keepSessionID uuid.UUID) error {
+	sessions, err := s.sessionStore.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.revokeSession(ctx, userID, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeSession ignores sessions that are already gone.
+func (s *AuthService) revokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	err := s.sessionStore.Revoke(ctx, userID, sessionID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// checkCanSignIn applies an admin's block. startSession and issueMFAToken run
+// it, so every sign-in method honours it whichever way it ends.
+func checkCanSignIn(user *domain.User) error {
+	if user.IsDisabled() {
+		return domain.ErrAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return domain.ErrPasswordResetNeeded
+	}
+	return nil
+}
+
+func (s *AuthService) startSession(
+	ctx context.Context,
+	user *domain.User,
+	device domain.DeviceInfo,
+	mfa bool,
+) (*domain.AuthTokens, error) {
+	if err := checkCanSignIn(user); err != nil {
+		return nil, err
+	}
+	ttl := s.cfg.RefreshExpirationHours
+	session, err := domain.NewSession(user.ID, device, mfa)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionStore.Create(ctx, session, ttl); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, session, user, ttl)
+}
+
+func (s *AuthService) issueTokens(
+	ctx context.Context,
+	session *domain.Session,
+	user *domain.User,
+	ttl time.Duration,
+) (*domain.AuthTokens, error) {
+	accessToken, err := s.tokenizer.GenerateToken(security.Claims{
+		Subject:       user.ID.String(),
+		Role:          user.Role.String(),
+		SessionID:     session.ID.String(),
+		EmailVerified: user.IsVerified(),
+		MFA:           session.MFA,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := domain.NewRefreshToken(session.ID, user.ID, ttl)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if !user.CheckPassword(password) {
-		return "", domain.ErrInvalidPassword
+	if err := s.tokenStore.Save(ctx, token); err != nil {
+		return nil, err
 	}
 
-	return s.tokenizer.GenerateToken(user.ID.String(), user.Role.String())
+	return &domain.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+)
+
+type memUsers struct {
+	domain.AuthRepository
+	users map[uuid.UUID]*domain.User
+}
+
+func newMemUsers(users ...*domain.User) *memUsers {
+	m := &memUsers{users: map[uuid.UUID]*domain.User{}}
+	for _, user := range users {
+		m.users[user.ID] = user
+	}
+	return m
+}
+
+func (m *memUsers) FindByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (m *memUsers) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	for _, user := range m.users {
+		if user.Email == domain.NormalizeEmail(email) {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (m *memUsers) Update(_ context.Context, user *domain.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+type memSessions struct {
+	domain.SessionStore
+	sessions map[uuid.UUID]*domain.Session
+}
+
+func newMemSessions() *memSessions {
+	return &memSessions{sessions: map[uuid.UUID]*domain.Session{}}
+}
+
+func (m *memSessions) Create(_ context.Context, session *domain.Session, _ time.Duration) error {
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memSessions) Get(_ context.Context, id uuid.UUID) (*domain.Session, error) {
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (m *memSessions) Extend(ctx context.Context, id uuid.UUID, _ time.Duration) error {
+	_, err := m.Get(ctx, id)
+	return err
+}
+
+func (m *memSessions) ListByUser(_ context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	var sessions []*domain.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memSessions) Revoke(_ context.Context, userID, id uuid.UUID) error {
+	session, ok := m.sessions[id]
+	if !ok || session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memSessions) RevokeAll(_ context.Context, userID uuid.UUID) error {
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// memRefreshTokens keeps used tokens around, as the Redis store does until
+// they expire, so reuse can be told apart from an unknown token.
+type memRefreshTokens struct {
+	tokens map[string]*domain.RefreshToken
+	used   map[string]bool
+}
+
+func newMemRefreshTokens() *memRefreshTokens {
+	return &memRefreshTokens{tokens: map[string]*domain.RefreshToken{}, used: map[string]bool{}}
+}
+
+func (m *memRefreshTokens) Save(_ context.Context, token *domain.RefreshToken) error {
+	m.tokens[token.Hash] = token
+	return nil
+}
+
+func (m *memRefreshTokens) Consume(_ context.Context, hash string) (*domain.RefreshToken, error) {
+	token, ok := m.tokens[hash]
+	if !ok {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if m.used[hash] {
+		return token, domain.ErrRefreshTokenReused
+	}
+	m.used[hash] = true
+	return token, nil
+}
+
+func newTestUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user, err := domain.NewUser(email, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func newSessionService(users *memUsers, sessions *memSessions, tokens *memRefreshTokens) *AuthService {
+	return &AuthService{
+		userRepo:     users,
+		eventRepo:    nopEvents{},
+		sessionStore: sessions,
+		tokenStore:   tokens,
+		tokenizer:    stubAccessTokens{},
+		cfg:          &config.JWTConfig{RefreshExpirationHours: time.Hour},
+		authCfg:      &config.AuthConfig{},
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		refreshes   int
+		replayFirst bool
+		disable     bool
+		wantErr     error
+		wantSession bool
+	}{
+		{name: "rotates", refreshes: 1, wantSession: true},
+		{name: "rotated token keeps rotating", refreshes: 3, wantSession: true},
+		{name: "reuse revokes the session", refreshes: 2, replayFirst: true, wantErr: domain.ErrRefreshTokenReused},
+		{name: "disabled user", refreshes: 1, disable: true, wantErr: domain.ErrInvalidRefreshToken, wantSession: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t, "ann@example.com")
+			sessions := newMemSessions()
+			s := newSessionService(newMemUsers(user), sessions, newMemRefreshTokens())
+
+			tokens, err := s.startSession(ctx, user, domain.DeviceInfo{}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.disable {
+				user.Disable("chargebacks")
+			}
+
+			first := tokens.RefreshToken
+			current := first
+			for i := range tt.refreshes {
+				presented := current
+				if tt.replayFirst && i > 0 {
+					presented = first
+				}
+				tokens, err = s.Refresh(ctx, presented, domain.DeviceInfo{})
+				if err != nil {
+					break
+				}
+				if tokens.RefreshToken == presented {
+					t.Fatal("Refresh() returned the presented token")
+				}
+				current = tokens.RefreshToken
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(sessions.sessions) == 1; got != tt.wantSession {
+				t.Errorf("session kept = %v, want %v", got, tt.wantSession)
+			}
+			if tt.replayFirst {
+				// The legitimate holder's newest token dies with the session.
+				if _, err := s.Refresh(ctx, current, domain.DeviceInfo{}); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+					t.Errorf("Refresh() with the newest token error = %v, want %v", err, domain.ErrInvalidRefreshToken)
+				}
+			}
+		})
+	}
+}
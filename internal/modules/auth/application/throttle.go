@@ -0,0 +1,65 @@
+package application
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/pkg/auth"
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so a
+// sign-in for a missing account costs the same as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := auth.HashPassword("bobshop-timing-equalizer")
+	return hash
+})
+
+func (s *AuthService) checkSignInLock(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		lockedFor, err := s.attemptStore.LockedFor(ctx, key)
+		if err != nil {
+			return err
+		}
+		if lockedFor > 0 {
+			return &domain.LockoutError{RetryAfter: lockedFor}
+		}
+	}
+	return nil
+}
+
+func (s *AuthService) registerFailedSignIn(ctx context.Context, email, ip string) error {
+	window := s.authCfg.FailedAttemptWindow
+	base, limit := s.authCfg.LockoutDuration, s.authCfg.MaxLockoutDuration
+
+	thresholds := map[string]int64{
+		domain.AccountAttemptKey(email): s.authCfg.MaxFailedAttempts,
+		domain.IPAttemptKey(ip):         s.authCfg.MaxFailedAttemptsPerIP,
+	}
+	for key, threshold := range thresholds {
+		failures, err := s.attemptStore.RegisterFailure(ctx, key, window)
+		if err != nil {
+			return err
+		}
+		if d := domain.LockoutDuration(failures, threshold, base, limit); d > 0 {
+			if err := s.attemptStore.Lock(ctx, key, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnlockAccount clears the failed sign-in counter and any lock on the account.
+func (s *AuthService) UnlockAccount(ctx context.Context, actorID, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.attemptStore.Reset(ctx, domain.AccountAttemptKey(user.Email)); err != nil {
+		return err
+	}
+	return s.recordAdminAction(ctx, adminEvent(domain.EventAccountUnlock, actorID, userID, ""))
+}
@@ -0,0 +1,309 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/pkg/auth"
+)
+
+const (
+	defaultWebAuthnChallengeTTL = 5 * time.Minute
+	defaultUserVerification     = "preferred"
+	defaultPasskeyName          = "Passkey"
+
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+)
+
+func (s *AuthService) BeginWebAuthnRegistration(ctx context.Context, userID uuid.UUID) (*domain.WebAuthnOptions, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.newWebAuthnCeremony(ctx, domain.CeremonyRegistration, &user.ID)
+	if err != nil {
+		return nil, err
+	}
+	opts.User = user
+	for _, credential := range user.WebAuthnCredentials {
+		opts.ExcludeCredentials = append(opts.ExcludeCredentials, credential.ID)
+	}
+	return opts, nil
+}
+
+// FinishWebAuthnRegistration stores a new passkey for the signed-in user. The
+// attestation statement is not checked, so any authenticator is accepted.
+func (s *AuthService) FinishWebAuthnRegistration(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	clientDataJSON, attestationObject []byte,
+	device domain.DeviceInfo,
+) (*domain.WebAuthnCredential, error) {
+	credential, err := s.finishWebAuthnRegistration(ctx, userID, name, clientDataJSON, attestationObject)
+	s.recordOutcome(ctx, domain.EventPasskeyRegistered, userID, device, err)
+	return credential, err
+}
+
+func (s *AuthService) finishWebAuthnRegistration(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	clientDataJSON, attestationObject []byte,
+) (*domain.WebAuthnCredential, error) {
+	if _, err := s.consumeWebAuthnChallenge(ctx, clientDataJSON, clientDataTypeCreate, domain.CeremonyRegistration, &userID); err != nil {
+		return nil, err
+	}
+
+	_, rawAuthData, err := auth.ParseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	authData, err := s.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if len(authData.CredentialID) == 0 {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	alg, pub, err := auth.ParseCOSEKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.FindByWebAuthnCredential(ctx, authData.CredentialID); err == nil {
+		return nil, domain.ErrWebAuthnCredentialExists
+	} else if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	credential := domain.WebAuthnCredential{
+		ID:        authData.CredentialID,
+		PublicKey: publicKey,
+		Algorithm: alg,
+		SignCount: authData.SignCount,
+		AAGUID:    authData.AAGUID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	user.AddWebAuthnCredential(credential)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// BeginWebAuthnLogin starts a sign-in with a discoverable passkey; the
+// authenticator picks the account, so no email is asked for.
+func (s *AuthService) BeginWebAuthnLogin(ctx context.Context) (*domain.WebAuthnOptions, error) {
+	return s.newWebAuthnCeremony(ctx, domain.CeremonyAssertion, nil)
+}
+
+// FinishWebAuthnLogin signs in with a passkey. A user-verified assertion counts
+// as two factors; otherwise users with two-factor authentication still get an
+// MFA token.
+func (s *AuthService) FinishWebAuthnLogin(
+	ctx context.Context,
+	assertion domain.WebAuthnAssertion,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, error) {
+	result, user, err := s.finishWebAuthnLogin(ctx, assertion, device)
+	s.recordSignIn(ctx, methodWebAuthn, "", user, device, result, err)
+	return result, err
+}
+
+func (s *AuthService) finishWebAuthnLogin(
+	ctx context.Context,
+	assertion domain.WebAuthnAssertion,
+	device domain.DeviceInfo,
+) (*domain.SignInResult, *domain.User, error) {
+	if _, err := s.consumeWebAuthnChallenge(ctx, assertion.ClientDataJSON, clientDataTypeGet, domain.CeremonyAssertion, nil); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.FindByWebAuthnCredential(ctx, assertion.CredentialID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, domain.ErrWebAuthnCredentialUnknown
+		}
+		return nil, nil, err
+	}
+	if len(assertion.UserHandle) > 0 && !bytes.Equal(assertion.UserHandle, user.ID[:]) {
+		return nil, user, domain.ErrInvalidWebAuthnResponse
+	}
+	credential := user.WebAuthnCredential(assertion.CredentialID)
+
+	authData, err := s.verifyAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return nil, user, err
+	}
+	pub, err := x509.ParsePKIXPublicKey(credential.PublicKey)
+	if err != nil {
+		return nil, user, err
+	}
+	err = auth.VerifyWebAuthnSignature(
+		credential.Algorithm, pub, assertion.AuthenticatorData, assertion.ClientDataJSON, assertion.Signature,
+	)
+	if err != nil {
+		return nil, user, domain.ErrInvalidWebAuthnResponse
+	}
+	// A disabled account is refused before its counter moves, so attempts
+	// against it leave the stored credential untouched.
+	if user.IsDisabled() {
+		return nil, user, domain.ErrAccountDisabled
+	}
+	if err := credential.RecordUse(authData.SignCount); err != nil {
+		return nil, user, err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, user, err
+	}
+
+	if user.HasMFA() && !authData.UserVerified() {
+		mfaToken, err := s.issueMFAToken(ctx, user)
+		if err != nil {
+			return nil, user, err
+		}
+		return &domain.SignInResult{MFAToken: mfaToken}, user, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, device, authData.UserVerified())
+	if err != nil {
+		return nil, user, err
+	}
+	return &domain.SignInResult{Tokens: tokens}, user, nil
+}
+
+func (s *AuthService) ListWebAuthnCredentials(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.WebAuthnCredentials, nil
+}
+
+func (s *AuthService) DeleteWebAuthnCredential(
+	ctx context.Context,
+	userID uuid.UUID,
+	credentialID []byte,
+	device domain.DeviceInfo,
+) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.RemoveWebAuthnCredential(credentialID) {
+		return domain.ErrWebAuthnCredentialUnknown
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.recordOutcome(ctx, domain.EventPasskeyRemoved, userID, device, nil)
+	return nil
+}
+
+func (s *AuthService) newWebAuthnCeremony(
+	ctx context.Context,
+	ceremony domain.WebAuthnCeremony,
+	userID *uuid.UUID,
+) (*domain.WebAuthnOptions, error) {
+	cfg := s.authCfg.WebAuthn
+	if cfg.RPID == "" {
+		return nil, domain.ErrWebAuthnDisabled
+	}
+	ttl := defaultWebAuthnChallengeTTL
+	if cfg.ChallengeTTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(cfg.ChallengeTTL); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, err := domain.NewWebAuthnChallenge(ceremony, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webAuthnStore.Save(ctx, challenge, ttl); err != nil {
+		return nil, err
+	}
+
+	opts := &domain.WebAuthnOptions{
+		Challenge:        challenge.Challenge,
+		RPID:             cfg.RPID,
+		RPName:           cfg.RPName,
+		UserVerification: cfg.UserVerification,
+		Timeout:          ttl,
+	}
+	if opts.RPName == "" {
+		opts.RPName = cfg.RPID
+	}
+	if opts.UserVerification == "" {
+		opts.UserVerification = defaultUserVerification
+	}
+	return opts, nil
+}
+
+// consumeWebAuthnChallenge checks the client data the browser signed and uses
+// up the challenge it names, which must belong to the same ceremony and user.
+func (s *AuthService) consumeWebAuthnChallenge(
+	ctx context.Context,
+	clientDataJSON []byte,
+	clientDataType string,
+	ceremony domain.WebAuthnCeremony,
+	userID *uuid.UUID,
+) (*domain.WebAuthnChallenge, error) {
+	clientData, err := auth.ParseClientData(clientDataJSON)
+	if err != nil {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	if clientData.Type != clientDataType || !slices.Contains(s.authCfg.WebAuthn.Origins, clientData.Origin) {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+
+	challenge, err := s.webAuthnStore.Consume(ctx, clientData.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Ceremony != ceremony {
+		return nil, domain.ErrInvalidWebAuthnChallenge
+	}
+	if userID != nil && (challenge.UserID == nil || *challenge.UserID != *userID) {
+		return nil, domain.ErrInvalidWebAuthnChallenge
+	}
+	return challenge, nil
+}
+
+func (s *AuthService) verifyAuthenticatorData(raw []byte) (*auth.AuthenticatorData, error) {
+	authData, err := auth.ParseAuthenticatorData(raw)
+	if err != nil {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	rpIDHash := sha256.Sum256([]byte(s.authCfg.WebAuthn.RPID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) || !authData.UserPresent() {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	if s.authCfg.WebAuthn.UserVerification == "required" && !authData.UserVerified() {
+		return nil, domain.ErrInvalidWebAuthnResponse
+	}
+	return authData, nil
+}
@@ -0,0 +1,442 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ugorji/go/codec"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/security"
+	"bobshop/pkg/auth"
+)
+
+const (
+	testRPID   = "shop.example.com"
+	testOrigin = "https://shop.example.com"
+)
+
+// softAuthenticator is an ES256 passkey held in memory, producing the same
+// attestation objects and assertions a browser would pass on.
+type softAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	return &softAuthenticator{t: t, key: key, credentialID: id}
+}
+
+func (a *softAuthenticator) cbor(v any) []byte {
+	a.t.Helper()
+	var out []byte
+	if err := codec.NewEncoderBytes(&out, &codec.CborHandle{}).Encode(v); err != nil {
+		a.t.Fatal(err)
+	}
+	return out
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	pub, err := a.key.PublicKey.ECDH()
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	point := pub.Bytes() // 0x04 || X || Y
+	return a.cbor(map[int64]any{
+		1:  2,
+		3:  auth.COSEAlgES256,
+		-1: 1,
+		-2: point[1:33],
+		-3: point[33:65],
+	})
+}
+
+// authData builds authenticator data; attested adds the credential, as an
+// authenticator does when registering.
+func (a *softAuthenticator) authData(rpID string, flags byte, signCount uint32, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	if attested {
+		flags |= 0x40
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) attestationObject(authData []byte) []byte {
+	return a.cbor(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+}
+
+func (a *softAuthenticator) sign(authData, clientDataJSON []byte) []byte {
+	clientHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return sig
+}
+
+func (a *softAuthenticator) credential(signCount uint32) domain.WebAuthnCredential {
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return domain.WebAuthnCredential{
+		ID:        a.credentialID,
+		PublicKey: publicKey,
+		Algorithm: auth.COSEAlgES256,
+		SignCount: signCount,
+	}
+}
+
+func clientDataJSON(t *testing.T, typ, challenge, origin string) []byte {
+	t.Helper()
+	data, err := json.Marshal(auth.ClientData{Type: typ, Challenge: challenge, Origin: origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+type webAuthnUsers struct {
+	domain.AuthRepository
+	user    *domain.User
+	updates int
+}
+
+func (r *webAuthnUsers) FindByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	if r.user == nil || r.user.ID != id {
+		return nil, domain.ErrUserNotFound
+	}
+	return r.user, nil
+}
+
+func (r *webAuthnUsers) FindByWebAuthnCredential(_ context.Context, id []byte) (*domain.User, error) {
+	if r.user == nil || r.user.WebAuthnCredential(id) == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return r.user, nil
+}
+
+func (r *webAuthnUsers) Update(context.Context, *domain.User) error {
+	r.updates++
+	return nil
+}
+
+type memChallenges map[string]*domain.WebAuthnChallenge
+
+func (m memChallenges) Save(_ context.Context, challenge *domain.WebAuthnChallenge, _ time.Duration) error {
+	m[challenge.Challenge] = challenge
+	return nil
+}
+
+func (m memChallenges) Consume(_ context.Context, challenge string) (*domain.WebAuthnChallenge, error) {
+	c, ok := m[challenge]
+	if !ok {
+		return nil, domain.ErrInvalidWebAuthnChallenge
+	}
+	delete(m, challenge)
+	return c, nil
+}
+
+type nopEvents struct{ domain.AuthEventRepository }
+
+func (nopEvents) Record(context.Context, *domain.AuthEvent) error { return nil }
+
+type nopSessions struct{ domain.SessionStore }
+
+func (nopSessions) Create(context.Context, *domain.Session, time.Duration) error { return nil }
+
+type nopRefreshTokens struct{ domain.RefreshTokenStore }
+
+func (nopRefreshTokens) Save(context.Context, *domain.RefreshToken) error { return nil }
+
+type stubAccessTokens struct{ security.Tokenizer }
+
+func (stubAccessTokens) GenerateToken(security.Claims) (string, error) { return "access", nil }
+
+func newWebAuthnService(users *webAuthnUsers, challenges memChallenges) *AuthService {
+	return &AuthService{
+		userRepo:      users,
+		eventRepo:     nopEvents{},
+		sessionStore:  nopSessions{},
+		tokenStore:    nopRefreshTokens{},
+		webAuthnStore: challenges,
+		tokenizer:     stubAccessTokens{},
+		cfg:           &config.JWTConfig{RefreshExpirationHours: time.Hour},
+		authCfg: &config.AuthConfig{
+			WebAuthn: config.WebAuthnConfig{RPID: testRPID, Origins: []string{testOrigin}},
+		},
+	}
+}
+
+// webAuthnCeremony is what the authenticator and browser put into one
+// response; each test case changes one part of it.
+type webAuthnCeremony struct {
+	challenge string
+	typ       string
+	origin    string
+	rpID      string
+	flags     byte
+	signCount uint32
+}
+
+func TestFinishWebAuthnRegistration(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		mutate  func(c *webAuthnCeremony, s *AuthService, other uuid.UUID)
+		wantErr error
+	}{
+		{name: "valid", mutate: func(*webAuthnCeremony, *AuthService, uuid.UUID) {}},
+		{
+			name:    "unknown challenge",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ uuid.UUID) { c.challenge = "forged" },
+			wantErr: domain.ErrInvalidWebAuthnChallenge,
+		},
+		{
+			name: "sign-in challenge",
+			mutate: func(c *webAuthnCeremony, s *AuthService, _ uuid.UUID) {
+				opts, _ := s.BeginWebAuthnLogin(ctx)
+				c.challenge = opts.Challenge
+			},
+			wantErr: domain.ErrInvalidWebAuthnChallenge,
+		},
+		{
+			name: "another user's challenge",
+			mutate: func(c *webAuthnCeremony, s *AuthService, other uuid.UUID) {
+				challenge, _ := domain.NewWebAuthnChallenge(domain.CeremonyRegistration, &other)
+				_ = s.webAuthnStore.Save(ctx, challenge, time.Minute)
+				c.challenge = challenge.Challenge
+			},
+			wantErr: domain.ErrInvalidWebAuthnChallenge,
+		},
+		{
+			name:    "other origin",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ uuid.UUID) { c.origin = "https://evil.example" },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "sign-in client data",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ uuid.UUID) { c.typ = clientDataTypeGet },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "other relying party",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ uuid.UUID) { c.rpID = "evil.example" },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "user not present",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ uuid.UUID) { c.flags = auth.AuthenticatorUserVerified },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authr := newSoftAuthenticator(t)
+			user := &domain.User{ID: uuid.New()}
+			users := &webAuthnUsers{user: user}
+			s := newWebAuthnService(users, memChallenges{})
+
+			opts, err := s.BeginWebAuthnRegistration(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := webAuthnCeremony{
+				challenge: opts.Challenge,
+				typ:       clientDataTypeCreate,
+				origin:    testOrigin,
+				rpID:      testRPID,
+				flags:     auth.AuthenticatorUserPresent,
+			}
+			tt.mutate(&c, s, uuid.New())
+
+			attestation := authr.attestationObject(authr.authData(c.rpID, c.flags, c.signCount, true))
+			credential, err := s.FinishWebAuthnRegistration(
+				ctx, user.ID, "", clientDataJSON(t, c.typ, c.challenge, c.origin), attestation, domain.DeviceInfo{},
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FinishWebAuthnRegistration() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(user.WebAuthnCredentials) != 0 {
+					t.Error("a rejected passkey was stored")
+				}
+				return
+			}
+			if !bytes.Equal(credential.ID, authr.credentialID) || credential.Name != defaultPasskeyName {
+				t.Errorf("stored credential = %+v", credential)
+			}
+			if user.WebAuthnCredential(authr.credentialID) == nil {
+				t.Error("passkey not added to the user")
+			}
+		})
+	}
+}
+
+func TestFinishWebAuthnLogin(t *testing.T) {
+	ctx := context.Background()
+	const storedCount = 10
+
+	tests := []struct {
+		name      string
+		mutate    func(c *webAuthnCeremony, s *AuthService, user *domain.User)
+		tamper    func(a *domain.WebAuthnAssertion)
+		wantErr   error
+		wantCount uint32
+	}{
+		{name: "valid", wantCount: storedCount + 1},
+		{
+			name:    "unknown challenge",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ *domain.User) { c.challenge = "forged" },
+			wantErr: domain.ErrInvalidWebAuthnChallenge,
+		},
+		{
+			name: "registration challenge",
+			mutate: func(c *webAuthnCeremony, s *AuthService, user *domain.User) {
+				opts, _ := s.BeginWebAuthnRegistration(ctx, user.ID)
+				c.challenge = opts.Challenge
+			},
+			wantErr: domain.ErrInvalidWebAuthnChallenge,
+		},
+		{
+			name:    "other origin",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ *domain.User) { c.origin = "https://evil.example" },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "registration client data",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ *domain.User) { c.typ = clientDataTypeCreate },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "other relying party",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ *domain.User) { c.rpID = "evil.example" },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "user not present",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ *domain.User) { c.flags = auth.AuthenticatorUserVerified },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "signature over other data",
+			tamper:  func(a *domain.WebAuthnAssertion) { a.Signature[len(a.Signature)-1] ^= 0xff },
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name: "another user's handle",
+			tamper: func(a *domain.WebAuthnAssertion) {
+				other := uuid.New()
+				a.UserHandle = other[:]
+			},
+			wantErr: domain.ErrInvalidWebAuthnResponse,
+		},
+		{
+			name:    "unknown credential",
+			tamper:  func(a *domain.WebAuthnAssertion) { a.CredentialID = []byte("unknown") },
+			wantErr: domain.ErrWebAuthnCredentialUnknown,
+		},
+		{
+			name:    "sign count regression",
+			mutate:  func(c *webAuthnCeremony, _ *AuthService, _ *domain.User) { c.signCount = storedCount - 5 },
+			wantErr: domain.ErrWebAuthnSignCount,
+		},
+		{
+			name:    "disabled user",
+			mutate:  func(_ *webAuthnCeremony, _ *AuthService, user *domain.User) { user.Disable("fraud") },
+			wantErr: domain.ErrAccountDisabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authr := newSoftAuthenticator(t)
+			user := &domain.User{ID: uuid.New()}
+			user.AddWebAuthnCredential(authr.credential(storedCount))
+			users := &webAuthnUsers{user: user}
+			s := newWebAuthnService(users, memChallenges{})
+
+			opts, err := s.BeginWebAuthnLogin(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := webAuthnCeremony{
+				challenge: opts.Challenge,
+				typ:       clientDataTypeGet,
+				origin:    testOrigin,
+				rpID:      testRPID,
+				flags:     auth.AuthenticatorUserPresent | auth.AuthenticatorUserVerified,
+				signCount: storedCount + 1,
+			}
+			if tt.mutate != nil {
+				tt.mutate(&c, s, user)
+			}
+
+			authData := authr.authData(c.rpID, c.flags, c.signCount, false)
+			clientData := clientDataJSON(t, c.typ, c.challenge, c.origin)
+			assertion := domain.WebAuthnAssertion{
+				CredentialID:      authr.credentialID,
+				ClientDataJSON:    clientData,
+				AuthenticatorData: authData,
+				Signature:         authr.sign(authData, clientData),
+				UserHandle:        user.ID[:],
+			}
+			if tt.tamper != nil {
+				tt.tamper(&assertion)
+			}
+
+			result, err := s.FinishWebAuthnLogin(ctx, assertion, domain.DeviceInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FinishWebAuthnLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			stored := user.WebAuthnCredential(authr.credentialID)
+			if tt.wantErr != nil {
+				if stored.SignCount != storedCount || users.updates != 0 {
+					t.Errorf("rejected sign-in changed the credential: count %d, %d updates", stored.SignCount, users.updates)
+				}
+				return
+			}
+			if result.Tokens == nil || result.MFAToken != "" {
+				t.Errorf("result = %+v, want tokens", result)
+			}
+			if stored.SignCount != tt.wantCount || stored.LastUsedAt == nil {
+				t.Errorf("credential count = %d, last used %v; want %d and a time", stored.SignCount, stored.LastUsedAt, tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/delivery/http/dto"
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/web"
+)
+
+const defaultUserListLimit = 20
+
+func (h *AuthHandler) ListUsers(c *gin.Context) {
+	var req dto.ListUsersRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid query", err)
+		return
+	}
+
+	filter := domain.UserFilter{Query: req.Query, Disabled: req.Disabled}
+	if req.Role != "" {
+		role, err := domain.ParseRole(req.Role)
+		if err != nil {
+			response.BadRequest(c, "invalid role", err)
+			return
+		}
+		filter.Role = &role
+	}
+	var cursor *uuid.UUID
+	if req.Cursor != "" {
+		id := uuid.MustParse(req.Cursor)
+		cursor = &id
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultUserListLimit
+	}
+
+	users, nextCursor, err := h.authService.ListUsers(c.Request.Context(), filter, cursor, limit)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Users listed", dto.ToUserListResponse(users, nextCursor))
+}
+
+func (h *AuthHandler) GetUser(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	user, err := h.authService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User retrieved", dto.ToAdminUserResponse(user))
+}
+
+func (h *AuthHandler) ChangeRole(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+	role, err := domain.ParseRole(req.Role)
+	if err != nil {
+		response.BadRequest(c, "invalid role", err)
+		return
+	}
+
+	if err := h.authService.ChangeRole(c.Request.Context(), web.GetUserID(c), userID, role); err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Role changed")
+}
+
+func (h *AuthHandler) DisableUser(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	var req dto.DisableUserRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.DisableUser(c.Request.Context(), web.GetUserID(c), userID, req.Reason); err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "User disabled")
+}
+
+func (h *AuthHandler) EnableUser(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	if err := h.authService.EnableUser(c.Request.Context(), web.GetUserID(c), userID); err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "User enabled")
+}
+
+func (h *AuthHandler) ForcePasswordReset(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	if err := h.authService.ForcePasswordReset(c.Request.Context(), web.GetUserID(c), userID); err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Password reset required")
+}
+
+func (h *AuthHandler) ListAdminAudit(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	events, err := h.authService.ListAdminAudit(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Audit entries listed", dto.ToAuthEventResponses(events))
+}
+
+// Impersonate hands the token back in the body rather than as cookies, so the
+// admin's own browser session is left alone.
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	imp, err := h.authService.Impersonate(c.Request.Context(), web.GetUserID(c), userID, deviceInfo(c))
+	if err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Impersonation started", dto.ToImpersonationResponse(imp))
+}
+
+func (h *AuthHandler) StopImpersonation(c *gin.Context) {
+	actorID, ok := web.GetActorID(c)
+	if !ok {
+		response.BadRequest(c, "not impersonating", domain.ErrNotImpersonating)
+		return
+	}
+
+	sessionID, ok := web.GetSessionID(c)
+	if !ok {
+		response.Unauthorized(c, domain.ErrSessionNotFound)
+		return
+	}
+
+	err := h.authService.StopImpersonation(
+		c.Request.Context(), actorID, web.GetUserID(c), sessionID, deviceInfo(c),
+	)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Impersonation stopped")
+}
+
+func adminActionFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		response.NotFound(c, err)
+	case errors.Is(err, domain.ErrCannotModifySelf):
+		response.BadRequest(c, err.Error(), err)
+	case errors.Is(err, domain.ErrCannotImpersonate):
+		response.Forbidden(c, err)
+	default:
+		response.InternalError(c, err)
+	}
+}
+
+func (h *AuthHandler) ListAuthEvents(c *gin.Context) {
+	var req dto.ListAuthEventsRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid query", err)
+		return
+	}
+
+	filter := domain.AuthEventFilter{
+		Type: domain.AuthEventType(req.Type),
+		From: req.From,
+		To:   req.To,
+	}
+	if req.UserID != "" {
+		userID := uuid.MustParse(req.UserID)
+		filter.UserID = &userID
+	}
+	var cursor *uuid.UUID
+	if req.Cursor != "" {
+		id := uuid.MustParse(req.Cursor)
+		cursor = &id
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultUserListLimit
+	}
+
+	events, nextCursor, err := h.authService.ListAuthEvents(c.Request.Context(), filter, cursor, limit)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Auth events listed", dto.ToAuthEventListResponse(events, nextCursor))
+}
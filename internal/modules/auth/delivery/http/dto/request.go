@@ -1,11 +1,150 @@
 package dto
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Base64URL is binary data carried as base64url, the encoding WebAuthn uses
+// in JSON. Padding is accepted but not produced.
+type Base64URL []byte
+
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
 type SignUpRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"`
 }
 
 type SignInRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFACodeRequest carries a TOTP or recovery code confirming a change to the
+// user's two-factor settings.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// UpdateProfileRequest is a partial update: omitted fields are unchanged and
+// an empty string clears a field.
+type UpdateProfileRequest struct {
+	DisplayName      *string `json:"display_name" validate:"omitempty,max=100"`
+	Phone            *string `json:"phone" validate:"omitempty,e164|len=0"`
+	AvatarURL        *string `json:"avatar_url" validate:"omitempty,url|len=0,max=2048"`
+	Locale           *string `json:"locale" validate:"omitempty,bcp47_language_tag|len=0"`
+	MarketingConsent *bool   `json:"marketing_consent"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ListUsersRequest struct {
+	Query    string `form:"q" validate:"omitempty,max=100"`
+	Role     string `form:"role" validate:"omitempty,oneof=user admin"`
+	Disabled *bool  `form:"disabled"`
+	Cursor   string `form:"cursor" validate:"omitempty,uuid"`
+	Limit    int    `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type DisableUserRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type ListAuthEventsRequest struct {
+	UserID string     `form:"user_id" validate:"omitempty,uuid"`
+	Type   string     `form:"type" validate:"omitempty,max=50"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor string     `form:"cursor" validate:"omitempty,uuid"`
+	Limit  int        `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// The WebAuthn requests take the JSON form of a PublicKeyCredential, as
+// produced by PublicKeyCredential.toJSON(), hence the camelCase names.
+type WebAuthnRegistrationRequest struct {
+	Name     string                      `json:"name" validate:"omitempty,max=64"`
+	Type     string                      `json:"type" validate:"required,eq=public-key"`
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    Base64URL `json:"clientDataJSON" validate:"required"`
+	AttestationObject Base64URL `json:"attestationObject" validate:"required"`
+}
+
+type WebAuthnLoginRequest struct {
+	RawID    Base64URL                 `json:"rawId" validate:"required"`
+	Type     string                    `json:"type" validate:"required,eq=public-key"`
+	Response WebAuthnAssertionResponse `json:"response"`
+}
+
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    Base64URL `json:"clientDataJSON" validate:"required"`
+	AuthenticatorData Base64URL `json:"authenticatorData" validate:"required"`
+	Signature         Base64URL `json:"signature" validate:"required"`
+	UserHandle        Base64URL `json:"userHandle"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestUpdateProfileRequestValidation(t *testing.T) {
+	validate := validator.New()
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		req     UpdateProfileRequest
+		wantErr bool
+	}{
+		{name: "nothing changed", req: UpdateProfileRequest{}},
+		{name: "valid values", req: UpdateProfileRequest{
+			Phone:     str("+84901234567"),
+			AvatarURL: str("https://cdn.example.com/a.png"),
+			Locale:    str("vi-VN"),
+		}},
+		{name: "clear phone", req: UpdateProfileRequest{Phone: str("")}},
+		{name: "clear avatar", req: UpdateProfileRequest{AvatarURL: str("")}},
+		{name: "clear locale", req: UpdateProfileRequest{Locale: str("")}},
+		{name: "invalid phone", req: UpdateProfileRequest{Phone: str("0901234567")}, wantErr: true},
+		{name: "invalid avatar", req: UpdateProfileRequest{AvatarURL: str("not a url")}, wantErr: true},
+		{name: "invalid locale", req: UpdateProfileRequest{Locale: str("not_a_locale!")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,5 +1,305 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/pkg/auth"
+)
+
 type SignInResponse struct {
-	Email string `json:"email"`
+	Email       string `json:"email"`
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
+
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type CSRFTokenResponse struct {
+	Token  string `json:"csrf_token"`
+	Header string `json:"header"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+	// Impersonated marks a session opened by support staff.
+	Impersonated bool `json:"impersonated"`
+}
+
+func ToSessionResponses(sessions []*domain.Session, currentID uuid.UUID) []*SessionResponse {
+	res := make([]*SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, &SessionResponse{
+			ID:           s.ID,
+			UserAgent:    s.UserAgent,
+			IP:           s.IP,
+			CreatedAt:    s.CreatedAt,
+			LastSeenAt:   s.LastSeenAt,
+			Current:      s.ID == currentID,
+			Impersonated: s.ImpersonatorID != nil,
+		})
+	}
+	return res
+}
+
+type ProfileResponse struct {
+	ID               uuid.UUID `json:"id"`
+	Email            string    `json:"email"`
+	PendingEmail     string    `json:"pending_email,omitempty"`
+	EmailVerified    bool      `json:"email_verified"`
+	Role             string    `json:"role"`
+	MFAEnabled       bool      `json:"mfa_enabled"`
+	DisplayName      string    `json:"display_name"`
+	Phone            string    `json:"phone"`
+	AvatarURL        string    `json:"avatar_url"`
+	Locale           string    `json:"locale"`
+	MarketingConsent bool      `json:"marketing_consent"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func ToProfileResponse(u *domain.User) *ProfileResponse {
+	res := &ProfileResponse{
+		ID:               u.ID,
+		Email:            u.Email,
+		EmailVerified:    u.IsVerified(),
+		Role:             u.Role.String(),
+		MFAEnabled:       u.HasMFA(),
+		DisplayName:      u.Profile.DisplayName,
+		Phone:            u.Profile.Phone,
+		AvatarURL:        u.Profile.AvatarURL,
+		Locale:           u.Profile.Locale,
+		MarketingConsent: u.Profile.MarketingConsent,
+		CreatedAt:        u.CreatedAt,
+	}
+	if u.EmailChange != nil {
+		res.PendingEmail = u.EmailChange.Email
+	}
+	return res
+}
+
+type AdminUserResponse struct {
+	*ProfileResponse
+	DisabledAt            *time.Time `json:"disabled_at"`
+	DisabledReason        string     `json:"disabled_reason,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+func ToAdminUserResponse(u *domain.User) *AdminUserResponse {
+	return &AdminUserResponse{
+		ProfileResponse:       ToProfileResponse(u),
+		DisabledAt:            u.DisabledAt,
+		DisabledReason:        u.DisabledReason,
+		PasswordResetRequired: u.PasswordResetRequired,
+		UpdatedAt:             u.UpdatedAt,
+	}
+}
+
+type UserListResponse struct {
+	Users      []*AdminUserResponse `json:"users"`
+	NextCursor *uuid.UUID           `json:"next_cursor"`
+}
+
+func ToUserListResponse(users []*domain.User, nextCursor *uuid.UUID) *UserListResponse {
+	res := &UserListResponse{
+		Users:      make([]*AdminUserResponse, 0, len(users)),
+		NextCursor: nextCursor,
+	}
+	for _, u := range users {
+		res.Users = append(res.Users, ToAdminUserResponse(u))
+	}
+	return res
+}
+
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	UserID      uuid.UUID `json:"user_id"`
+	SessionID   uuid.UUID `json:"session_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func ToImpersonationResponse(imp *domain.Impersonation) *ImpersonationResponse {
+	return &ImpersonationResponse{
+		AccessToken: imp.AccessToken,
+		TokenType:   "Bearer",
+		UserID:      imp.UserID,
+		SessionID:   imp.SessionID,
+		ExpiresAt:   imp.ExpiresAt,
+	}
+}
+
+type AuthEventResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Outcome   string     `json:"outcome"`
+	Reason    string     `json:"reason,omitempty"`
+	Method    string     `json:"method,omitempty"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	Email     string     `json:"email,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type AuthEventListResponse struct {
+	Events     []*AuthEventResponse `json:"events"`
+	NextCursor *uuid.UUID           `json:"next_cursor"`
+}
+
+func ToAuthEventListResponse(events []*domain.AuthEvent, nextCursor *uuid.UUID) *AuthEventListResponse {
+	return &AuthEventListResponse{
+		Events:     ToAuthEventResponses(events),
+		NextCursor: nextCursor,
+	}
+}
+
+func ToAuthEventResponses(events []*domain.AuthEvent) []*AuthEventResponse {
+	res := make([]*AuthEventResponse, 0, len(events))
+	for _, e := range events {
+		res = append(res, &AuthEventResponse{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			Outcome:   string(e.Outcome),
+			Reason:    e.Reason,
+			Method:    e.Method,
+			UserID:    e.UserID,
+			ActorID:   e.ActorID,
+			Email:     e.Email,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return res
+}
+
+// WebAuthnCreationOptions and WebAuthnRequestOptions are the JSON forms the
+// browser's PublicKeyCredential.parse*OptionsFromJSON() accept.
+type WebAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RPID             string                         `json:"rpId"`
+	Timeout          int64                          `json:"timeout"`
+	UserVerification string                         `json:"userVerification"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+}
+
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUserEntity struct {
+	ID          Base64URL `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type string    `json:"type"`
+	ID   Base64URL `json:"id"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// ToWebAuthnCreationOptions asks for a discoverable credential so the passkey
+// can later sign in without an email.
+func ToWebAuthnCreationOptions(opts *domain.WebAuthnOptions) *WebAuthnCreationOptions {
+	res := &WebAuthnCreationOptions{
+		Challenge: opts.Challenge,
+		RP:        WebAuthnRelyingParty{ID: opts.RPID, Name: opts.RPName},
+		User: WebAuthnUserEntity{
+			ID:          opts.User.ID[:],
+			Name:        opts.User.Email,
+			DisplayName: opts.User.Profile.DisplayName,
+		},
+		PubKeyCredParams:   make([]WebAuthnCredentialParameter, 0, len(auth.SupportedCOSEAlgorithms)),
+		Timeout:            opts.Timeout.Milliseconds(),
+		ExcludeCredentials: make([]WebAuthnCredentialDescriptor, 0, len(opts.ExcludeCredentials)),
+		AuthenticatorSelection: WebAuthnAuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   opts.UserVerification,
+		},
+		Attestation: "none",
+	}
+	if res.User.DisplayName == "" {
+		res.User.DisplayName = opts.User.Email
+	}
+	for _, alg := range auth.SupportedCOSEAlgorithms {
+		res.PubKeyCredParams = append(res.PubKeyCredParams, WebAuthnCredentialParameter{Type: "public-key", Alg: alg})
+	}
+	for _, id := range opts.ExcludeCredentials {
+		res.ExcludeCredentials = append(res.ExcludeCredentials, WebAuthnCredentialDescriptor{Type: "public-key", ID: id})
+	}
+	return res
+}
+
+func ToWebAuthnRequestOptions(opts *domain.WebAuthnOptions) *WebAuthnRequestOptions {
+	return &WebAuthnRequestOptions{
+		Challenge:        opts.Challenge,
+		RPID:             opts.RPID,
+		Timeout:          opts.Timeout.Milliseconds(),
+		UserVerification: opts.UserVerification,
+		AllowCredentials: []WebAuthnCredentialDescriptor{},
+	}
+}
+
+type WebAuthnCredentialResponse struct {
+	ID         Base64URL  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func ToWebAuthnCredentialResponse(credential *domain.WebAuthnCredential) *WebAuthnCredentialResponse {
+	return &WebAuthnCredentialResponse{
+		ID:         credential.ID,
+		Name:       credential.Name,
+		CreatedAt:  credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}
+}
+
+func ToWebAuthnCredentialResponses(credentials []domain.WebAuthnCredential) []*WebAuthnCredentialResponse {
+	res := make([]*WebAuthnCredentialResponse, 0, len(credentials))
+	for i := range credentials {
+		res = append(res, ToWebAuthnCredentialResponse(&credentials[i]))
+	}
+	return res
 }
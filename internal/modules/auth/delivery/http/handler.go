@@ -3,6 +3,8 @@ package http
 import (
 	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,25 +13,35 @@ import (
 	"bobshop/internal/modules/auth/application"
 	"bobshop/internal/modules/auth/delivery/http/dto"
 	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
 	"bobshop/internal/platform/response"
 	"bobshop/internal/platform/web"
 )
 
-var (
-	accessTokenName = "access_token"
+const (
+	oidcStateCookieName      = "oidc_state"
+	oidcCookiePath           = "/api/v1/auth/oidc"
+	magicLinkNonceCookieName = "magic_link_nonce"
+	magicLinkCookiePath      = "/api/v1/auth/magic-link"
 )
 
 type AuthHandler struct {
 	authService   *application.AuthService
 	validate      *validator.Validate
 	cookieManager *web.CookieManager
+	oidcCfg       *config.OIDCConfig
 }
 
-func NewAuthHandler(authService *application.AuthService, cookieManager *web.CookieManager) *AuthHandler {
+func NewAuthHandler(
+	authService *application.AuthService,
+	cookieManager *web.CookieManager,
+	oidcCfg *config.OIDCConfig,
+) *AuthHandler {
 	return &AuthHandler{
 		authService:   authService,
 		validate:      validator.New(),
 		cookieManager: cookieManager,
+		oidcCfg:       oidcCfg,
 	}
 }
 
@@ -46,6 +58,9 @@ func (h *AuthHandler) SignUp(c *gin.Context) {
 
 	err := h.authService.SignUp(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
+		if passwordPolicyFailed(c, "password", err) {
+			return
+		}
 		if errors.Is(err, domain.ErrUserAlreadyExists) {
 			response.Conflict(c, "user already exists", err)
 			return
@@ -68,28 +83,614 @@ func (h *AuthHandler) SignIn(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.SignIn(c.Request.Context(), req.Email, req.Password)
+	result, err := h.authService.SignIn(c.Request.Context(), req.Email, req.Password, deviceInfo(c))
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrInvalidPassword) {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
 			response.Unauthorized(c, err)
 			return
 		}
+		if accountBlocked(c, err) {
+			return
+		}
+		var lockout *domain.LockoutError
+		if errors.As(err, &lockout) {
+			c.Header("Retry-After", strconv.Itoa(int(lockout.RetryAfter.Seconds())+1))
+			response.TooManyRequests(c, "too many failed sign-in attempts", err)
+			return
+		}
 		response.InternalError(c, err)
 		return
 	}
 
-	cookie := h.cookieManager.BuildCookie(accessTokenName, token, h.cookieManager.GetMaxAge())
+	if result.MFAToken != "" {
+		response.Success(c, http.StatusOK, "Two-factor authentication required", dto.SignInResponse{
+			Email:       req.Email,
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+		})
+		return
+	}
 
-	http.SetCookie(c.Writer, cookie)
+	if err := h.setTokenCookies(c, result.Tokens); err != nil {
+		response.InternalError(c, err)
+		return
+	}
 
 	response.Success(c, http.StatusOK, "Signed in successfully", dto.SignInResponse{
 		Email: req.Email,
 	})
 }
 
+func (h *AuthHandler) StartOIDC(c *gin.Context) {
+	authURL, req, err := h.authService.StartOIDC(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownProvider) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	ttl, err := h.authService.OIDCStateTTL()
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	http.SetCookie(c.Writer, h.callbackCookie(oidcStateCookieName, oidcCookiePath, req.State, int(ttl.Seconds())))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback requires the state to match the cookie set by StartOIDC, so a
+// callback URL cannot be replayed in someone else's browser.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		response.Unauthorized(c, errors.New(providerErr))
+		return
+	}
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(oidcStateCookieName)
+	http.SetCookie(c.Writer, h.callbackCookie(oidcStateCookieName, oidcCookiePath, "", -1))
+	if state == "" || state != cookieState {
+		response.BadRequest(c, "invalid login state", domain.ErrInvalidOIDCState)
+		return
+	}
+
+	result, err := h.authService.CompleteOIDC(c.Request.Context(), c.Param("provider"), state, c.Query("code"), deviceInfo(c))
+	if err != nil {
+		if accountBlocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrInvalidOIDCState):
+			response.BadRequest(c, "invalid login state", err)
+		case errors.Is(err, domain.ErrOIDCEmailUnverified):
+			response.Forbidden(c, err)
+		case errors.Is(err, domain.ErrUnknownProvider):
+			response.NotFound(c, err)
+		default:
+			response.Unauthorized(c, err)
+		}
+		return
+	}
+
+	h.finishRedirectSignIn(c, result)
+}
+
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req dto.MagicLinkRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	// Reusing the nonce keeps earlier links from this browser valid.
+	nonce, _ := c.Cookie(magicLinkNonceCookieName)
+	if nonce == "" {
+		var err error
+		if nonce, err = domain.NewMagicLinkNonce(); err != nil {
+			response.InternalError(c, err)
+			return
+		}
+	}
+
+	if err := h.authService.RequestMagicLink(c.Request.Context(), req.Email, nonce); err != nil {
+		var lockout *domain.LockoutError
+		if errors.As(err, &lockout) {
+			c.Header("Retry-After", strconv.Itoa(int(lockout.RetryAfter.Seconds())+1))
+			response.TooManyRequests(c, "too many sign-in links requested", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	ttl, err := h.authService.MagicLinkTTL()
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	http.SetCookie(c.Writer, h.callbackCookie(magicLinkNonceCookieName, magicLinkCookiePath, nonce, int(ttl.Seconds())))
+	response.Success(c, http.StatusAccepted, "If the email belongs to an account, a sign-in link is on its way", nil)
+}
+
+// MagicLinkCallback only accepts links opened in the browser that requested
+// them, which still holds the nonce cookie.
+func (h *AuthHandler) MagicLinkCallback(c *gin.Context) {
+	nonce, _ := c.Cookie(magicLinkNonceCookieName)
+
+	result, err := h.authService.CompleteMagicLink(c.Request.Context(), c.Query("token"), nonce, deviceInfo(c))
+	if err != nil {
+		if accountBlocked(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidActionToken) {
+			response.BadRequest(c, "invalid or expired link", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	http.SetCookie(c.Writer, h.callbackCookie(magicLinkNonceCookieName, magicLinkCookiePath, "", -1))
+	h.finishRedirectSignIn(c, result)
+}
+
+// finishRedirectSignIn completes a sign-in that arrived by browser redirect,
+// sending the user on to PostLoginRedirect when one is configured.
+func (h *AuthHandler) finishRedirectSignIn(c *gin.Context, result *domain.SignInResult) {
+	redirect := h.oidcCfg.PostLoginRedirect
+	if result.MFAToken != "" {
+		if redirect != "" {
+			// The fragment keeps the MFA token out of server logs.
+			c.Redirect(http.StatusFound, redirect+"#mfa_token="+url.QueryEscape(result.MFAToken))
+			return
+		}
+		response.Success(c, http.StatusOK, "Two-factor authentication required", dto.SignInResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+		})
+		return
+	}
+
+	if err := h.setTokenCookies(c, result.Tokens); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	if redirect != "" {
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+	response.SimpleSuccess(c, "Signed in successfully")
+}
+
+func (h *AuthHandler) SetupTOTP(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	setup, err := h.authService.SetupTOTP(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			response.Conflict(c, "two-factor authentication already enabled", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor setup started", dto.TOTPSetupResponse{
+		Secret:     setup.Secret,
+		OTPAuthURI: setup.URI,
+	})
+}
+
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	var req dto.ConfirmTOTPRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	codes, err := h.authService.ConfirmTOTP(c.Request.Context(), userID, req.Code, deviceInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+			response.Conflict(c, "two-factor authentication already enabled", err)
+		case errors.Is(err, domain.ErrMFASetupNotStarted), errors.Is(err, domain.ErrInvalidMFACode):
+			response.BadRequest(c, err.Error(), err)
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Two-factor authentication enabled", dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	var req dto.MFACodeRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.DisableTOTP(c.Request.Context(), userID, req.Code, deviceInfo(c)); err != nil {
+		mfaChangeFailed(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Two-factor authentication disabled")
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	var req dto.MFACodeRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code, deviceInfo(c))
+	if err != nil {
+		mfaChangeFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Recovery codes regenerated", dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func mfaChangeFailed(c *gin.Context, err error) {
+	var lockout *domain.LockoutError
+	switch {
+	case errors.Is(err, domain.ErrMFANotEnabled), errors.Is(err, domain.ErrInvalidMFACode):
+		response.BadRequest(c, err.Error(), err)
+	case errors.As(err, &lockout):
+		c.Header("Retry-After", strconv.Itoa(int(lockout.RetryAfter.Seconds())+1))
+		response.TooManyRequests(c, "too many wrong two-factor codes", err)
+	default:
+		response.InternalError(c, err)
+	}
+}
+
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dto.VerifyMFARequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	tokens, err := h.authService.VerifyMFA(c.Request.Context(), req.MFAToken, req.Code, deviceInfo(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidActionToken) || errors.Is(err, domain.ErrInvalidMFACode) {
+			response.Unauthorized(c, err)
+			return
+		}
+		if accountBlocked(c, err) {
+			return
+		}
+		var lockout *domain.LockoutError
+		if errors.As(err, &lockout) {
+			c.Header("Retry-After", strconv.Itoa(int(lockout.RetryAfter.Seconds())+1))
+			response.TooManyRequests(c, "too many wrong two-factor codes", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	if err := h.setTokenCookies(c, tokens); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Signed in successfully")
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	refreshToken, err := c.Cookie(web.RefreshTokenCookieName)
+	if err != nil {
+		response.Unauthorized(c, err)
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), refreshToken, deviceInfo(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			h.clearTokenCookies(c)
+			response.Unauthorized(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	if err := h.setTokenCookies(c, tokens); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Token refreshed")
+}
+
 func (h *AuthHandler) SignOut(c *gin.Context) {
-	cookie := h.cookieManager.BuildCookie(accessTokenName, "", -1)
-	http.SetCookie(c.Writer, cookie)
+	refreshToken, _ := c.Cookie(web.RefreshTokenCookieName)
+	if err := h.authService.SignOut(c.Request.Context(), refreshToken, deviceInfo(c)); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	h.clearTokenCookies(c)
 
 	response.Success(c, http.StatusOK, "Signed out successfully", nil)
 }
+
+func (h *AuthHandler) CSRFToken(c *gin.Context) {
+	token, err := h.setCSRFCookie(c)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "CSRF token issued", &dto.CSRFTokenResponse{
+		Token:  token,
+		Header: web.CSRFHeaderName,
+	})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, domain.ErrInvalidActionToken) {
+			response.BadRequest(c, "invalid or expired token", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Email verified")
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Verification email sent if the account exists")
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Password reset email sent if the account exists")
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.Password, deviceInfo(c)); err != nil {
+		if passwordPolicyFailed(c, "password", err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidActionToken) {
+			response.BadRequest(c, "invalid or expired token", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	h.clearTokenCookies(c)
+
+	response.SimpleSuccess(c, "Password reset")
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	var req dto.ChangePasswordRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	sessionID, ok := web.GetSessionID(c)
+	if !ok {
+		response.Unauthorized(c, domain.ErrSessionNotFound)
+		return
+	}
+
+	err := h.authService.ChangePassword(
+		c.Request.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword, deviceInfo(c),
+	)
+	if err != nil {
+		if passwordPolicyFailed(c, "new_password", err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidPassword) {
+			response.BadRequest(c, "current password is incorrect", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Password changed")
+}
+
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	userID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	if err := h.authService.UnlockAccount(c.Request.Context(), web.GetUserID(c), userID); err != nil {
+		adminActionFailed(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Account unlocked")
+}
+
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	currentID, _ := web.GetSessionID(c)
+	response.Success(c, http.StatusOK, "Sessions listed", dto.ToSessionResponses(sessions, currentID))
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	sessionID, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	if currentID, ok := web.GetSessionID(c); ok && sessionID == currentID {
+		h.clearTokenCookies(c)
+	}
+
+	response.SimpleSuccess(c, "Session revoked")
+}
+
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	userID := web.GetUserID(c)
+
+	if err := h.authService.RevokeAllSessions(c.Request.Context(), userID); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	h.clearTokenCookies(c)
+
+	response.SimpleSuccess(c, "Signed out everywhere")
+}
+
+// accountBlocked reports accounts an admin disabled or flagged for a password
+// reset with distinct codes so clients can explain what happened.
+func accountBlocked(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrAccountDisabled):
+		response.Error(c, http.StatusForbidden, "ACCOUNT_DISABLED", "account is disabled", err)
+	case errors.Is(err, domain.ErrPasswordResetNeeded):
+		response.Error(c, http.StatusForbidden, "PASSWORD_RESET_REQUIRED", "password reset required", err)
+	default:
+		return false
+	}
+	return true
+}
+
+// passwordPolicyFailed writes the broken password rules under the request
+// field they apply to.
+func passwordPolicyFailed(c *gin.Context, field string, err error) bool {
+	var policyErr *domain.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	response.ValidationFailed(c, "password does not meet the requirements", map[string][]domain.PasswordViolation{
+		field: policyErr.Violations,
+	})
+	return true
+}
+
+func deviceInfo(c *gin.Context) domain.DeviceInfo {
+	return domain.DeviceInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+// callbackCookie is SameSite=Lax because OIDC providers and mailed links
+// arrive with a cross-site top-level navigation, which a Strict cookie would
+// not survive.
+func (h *AuthHandler) callbackCookie(name, path, value string, maxAge int) *http.Cookie {
+	cookie := h.cookieManager.BuildCookie(name, value, maxAge)
+	cookie.Path = path
+	cookie.HttpOnly = true
+	if cookie.SameSite == http.SameSiteStrictMode {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	return cookie
+}
+
+// setTokenCookies also issues the CSRF cookie, so a client that just signed
+// in can make cookie-authenticated writes without fetching a token first.
+func (h *AuthHandler) setTokenCookies(c *gin.Context, tokens *domain.AuthTokens) error {
+	if _, err := h.setCSRFCookie(c); err != nil {
+		return err
+	}
+	http.SetCookie(c.Writer, h.cookieManager.BuildCookie(web.AccessTokenCookieName, tokens.AccessToken, h.cookieManager.GetMaxAge()))
+	http.SetCookie(c.Writer, h.cookieManager.BuildCookie(web.RefreshTokenCookieName, tokens.RefreshToken, h.cookieManager.GetRefreshMaxAge()))
+	return nil
+}
+
+// setCSRFCookie keeps an existing token so other open tabs stay valid, and
+// lives as long as the refresh cookie.
+func (h *AuthHandler) setCSRFCookie(c *gin.Context) (string, error) {
+	token, _ := c.Cookie(web.CSRFCookieName)
+	if token == "" {
+		var err error
+		if token, err = web.NewCSRFToken(); err != nil {
+			return "", err
+		}
+	}
+	http.SetCookie(c.Writer, h.cookieManager.BuildCSRFCookie(token, h.cookieManager.GetRefreshMaxAge()))
+	return token, nil
+}
+
+func (h *AuthHandler) clearTokenCookies(c *gin.Context) {
+	http.SetCookie(c.Writer, h.cookieManager.BuildCookie(web.AccessTokenCookieName, "", -1))
+	http.SetCookie(c.Writer, h.cookieManager.BuildCookie(web.RefreshTokenCookieName, "", -1))
+	http.SetCookie(c.Writer, h.cookieManager.BuildCSRFCookie("", -1))
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/web"
+)
+
+func TestSetTokenCookiesIssuesCSRFCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		existing  string
+		wantToken string
+	}{
+		{name: "new token"},
+		{name: "keeps existing token", existing: "tab-token", wantToken: "tab-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &AuthHandler{cookieManager: web.NewCookieManager(&config.CookieConfig{RefreshMaxAge: "24h"})}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/signin", nil)
+			if tt.existing != "" {
+				c.Request.AddCookie(&http.Cookie{Name: web.CSRFCookieName, Value: tt.existing})
+			}
+
+			if err := h.setTokenCookies(c, &domain.AuthTokens{AccessToken: "a", RefreshToken: "r"}); err != nil {
+				t.Fatalf("setTokenCookies() error = %v", err)
+			}
+
+			cookies := map[string]*http.Cookie{}
+			for _, cookie := range rec.Result().Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+			csrf := cookies[web.CSRFCookieName]
+			if csrf == nil || csrf.Value == "" {
+				t.Fatal("no CSRF cookie issued")
+			}
+			if tt.wantToken != "" && csrf.Value != tt.wantToken {
+				t.Errorf("CSRF token = %q, want %q", csrf.Value, tt.wantToken)
+			}
+			if csrf.HttpOnly {
+				t.Error("CSRF cookie must be readable from scripts")
+			}
+			refresh := cookies[web.RefreshTokenCookieName]
+			if refresh == nil || csrf.MaxAge != refresh.MaxAge {
+				t.Errorf("CSRF cookie should live as long as the refresh cookie")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/modules/auth/delivery/http/dto"
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/web"
+)
+
+func (h *AuthHandler) GetProfile(c *gin.Context) {
+	user, err := h.authService.GetProfile(c.Request.Context(), web.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Profile retrieved", dto.ToProfileResponse(user))
+}
+
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	var req dto.UpdateProfileRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	user, err := h.authService.UpdateProfile(c.Request.Context(), web.GetUserID(c), domain.ProfileUpdate{
+		DisplayName:      req.DisplayName,
+		Phone:            req.Phone,
+		AvatarURL:        req.AvatarURL,
+		Locale:           req.Locale,
+		MarketingConsent: req.MarketingConsent,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Profile updated", dto.ToProfileResponse(user))
+}
+
+func (h *AuthHandler) ChangeEmail(c *gin.Context) {
+	var req dto.ChangeEmailRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	err := h.authService.RequestEmailChange(c.Request.Context(), web.GetUserID(c), req.NewEmail, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPassword):
+			response.BadRequest(c, "password is incorrect", err)
+		case errors.Is(err, domain.ErrEmailUnchanged):
+			response.BadRequest(c, err.Error(), err)
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			response.Conflict(c, "email already in use", err)
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+
+	response.SimpleSuccess(c, "Confirmation email sent to the new address")
+}
+
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	var req dto.ConfirmEmailChangeRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	if err := h.authService.ConfirmEmailChange(c.Request.Context(), req.Token, deviceInfo(c)); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidActionToken):
+			response.BadRequest(c, "invalid or expired token", err)
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			response.Conflict(c, "email already in use", err)
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+
+	response.SimpleSuccess(c, "Email changed")
+}
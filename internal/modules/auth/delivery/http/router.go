@@ -1,12 +1,87 @@
 package http
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
 
-func RegisterRoutes(rg *gin.RouterGroup, handler *AuthHandler) {
+	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/security"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, authMiddleware gin.HandlerFunc, handler *AuthHandler) {
 	authRoutes := rg.Group("/auth")
 	{
 		authRoutes.POST("/signup", handler.SignUp)
 		authRoutes.POST("/signin", handler.SignIn)
+		authRoutes.POST("/refresh", handler.Refresh)
 		authRoutes.POST("/signout", handler.SignOut)
+		authRoutes.GET("/csrf", handler.CSRFToken)
+		authRoutes.POST("/verify-email", handler.VerifyEmail)
+		authRoutes.POST("/resend-verification", handler.ResendVerification)
+		authRoutes.POST("/password/forgot", handler.ForgotPassword)
+		authRoutes.POST("/password/reset", handler.ResetPassword)
+		authRoutes.POST("/email/confirm", handler.ConfirmEmailChange)
+		authRoutes.PUT("/password", authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.ChangePassword)
+		authRoutes.DELETE("/impersonation", authMiddleware, middleware.RequireUser(), handler.StopImpersonation)
+
+		authRoutes.POST("/magic-link", handler.RequestMagicLink)
+		authRoutes.GET("/magic-link/callback", handler.MagicLinkCallback)
+
+		authRoutes.GET("/oidc/:provider/start", handler.StartOIDC)
+		authRoutes.GET("/oidc/:provider/callback", handler.OIDCCallback)
+
+		mfa := authRoutes.Group("/2fa")
+		mfa.POST("/setup", authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.SetupTOTP)
+		mfa.POST("/confirm", authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.ConfirmTOTP)
+		mfa.POST("/verify", handler.VerifyMFA)
+		mfa.POST("/recovery-codes",
+			authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.RegenerateRecoveryCodes,
+		)
+		mfa.DELETE("", authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.DisableTOTP)
+
+		webauthn := authRoutes.Group("/webauthn")
+		webauthn.POST("/register/begin",
+			authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.BeginWebAuthnRegistration,
+		)
+		webauthn.POST("/register/finish",
+			authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.FinishWebAuthnRegistration,
+		)
+		webauthn.POST("/login/begin", handler.BeginWebAuthnLogin)
+		webauthn.POST("/login/finish", handler.FinishWebAuthnLogin)
+		webauthn.GET("/credentials", authMiddleware, middleware.RequireUser(), handler.ListWebAuthnCredentials)
+		webauthn.DELETE("/credentials/:id",
+			authMiddleware, middleware.RequireUser(), middleware.BlockImpersonation(), handler.DeleteWebAuthnCredential,
+		)
+
+		sessions := authRoutes.Group("/sessions", authMiddleware, middleware.RequireUser())
+		sessions.GET("", handler.ListSessions)
+		sessions.DELETE("/:id", middleware.BlockImpersonation(), handler.RevokeSession)
+		sessions.DELETE("", middleware.BlockImpersonation(), handler.RevokeAllSessions)
 	}
+
+	me := rg.Group("/me", authMiddleware, middleware.RequireUser())
+	{
+		me.GET("", handler.GetProfile)
+		me.PATCH("", handler.UpdateProfile)
+		me.POST("/email", middleware.BlockImpersonation(), handler.ChangeEmail)
+	}
+
+	adminUsers := rg.Group("/admin/users",
+		authMiddleware, middleware.RequireUser(), middleware.RequirePermission(security.PermUserManage),
+	)
+	{
+		adminUsers.GET("", handler.ListUsers)
+		adminUsers.GET("/:id", handler.GetUser)
+		adminUsers.GET("/:id/audit", handler.ListAdminAudit)
+		adminUsers.PUT("/:id/role", handler.ChangeRole)
+		adminUsers.POST("/:id/disable", handler.DisableUser)
+		adminUsers.POST("/:id/enable", handler.EnableUser)
+		adminUsers.POST("/:id/password-reset", handler.ForcePasswordReset)
+		adminUsers.POST("/:id/unlock", handler.UnlockAccount)
+		adminUsers.POST("/:id/impersonate", handler.Impersonate)
+	}
+
+	rg.GET("/admin/auth-events",
+		authMiddleware, middleware.RequireUser(), middleware.RequirePermission(security.PermAuditRead),
+		handler.ListAuthEvents,
+	)
 }
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/modules/auth/delivery/http/dto"
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/web"
+)
+
+func (h *AuthHandler) BeginWebAuthnRegistration(c *gin.Context) {
+	opts, err := h.authService.BeginWebAuthnRegistration(c.Request.Context(), web.GetUserID(c))
+	if err != nil {
+		webAuthnFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Passkey registration started", dto.ToWebAuthnCreationOptions(opts))
+}
+
+func (h *AuthHandler) FinishWebAuthnRegistration(c *gin.Context) {
+	var req dto.WebAuthnRegistrationRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	credential, err := h.authService.FinishWebAuthnRegistration(
+		c.Request.Context(), web.GetUserID(c), req.Name,
+		req.Response.ClientDataJSON, req.Response.AttestationObject, deviceInfo(c),
+	)
+	if err != nil {
+		webAuthnFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Passkey registered", dto.ToWebAuthnCredentialResponse(credential))
+}
+
+func (h *AuthHandler) BeginWebAuthnLogin(c *gin.Context) {
+	opts, err := h.authService.BeginWebAuthnLogin(c.Request.Context())
+	if err != nil {
+		webAuthnFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Passkey sign-in started", dto.ToWebAuthnRequestOptions(opts))
+}
+
+// FinishWebAuthnLogin issues the same cookies as SignIn.
+func (h *AuthHandler) FinishWebAuthnLogin(c *gin.Context) {
+	var req dto.WebAuthnLoginRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	result, err := h.authService.FinishWebAuthnLogin(c.Request.Context(), domain.WebAuthnAssertion{
+		CredentialID:      req.RawID,
+		ClientDataJSON:    req.Response.ClientDataJSON,
+		AuthenticatorData: req.Response.AuthenticatorData,
+		Signature:         req.Response.Signature,
+		UserHandle:        req.Response.UserHandle,
+	}, deviceInfo(c))
+	if err != nil {
+		if accountBlocked(c, err) {
+			return
+		}
+		webAuthnFailed(c, err)
+		return
+	}
+
+	if result.MFAToken != "" {
+		response.Success(c, http.StatusOK, "Two-factor authentication required", dto.SignInResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+		})
+		return
+	}
+
+	if err := h.setTokenCookies(c, result.Tokens); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Signed in successfully")
+}
+
+func (h *AuthHandler) ListWebAuthnCredentials(c *gin.Context) {
+	credentials, err := h.authService.ListWebAuthnCredentials(c.Request.Context(), web.GetUserID(c))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Passkeys listed", dto.ToWebAuthnCredentialResponses(credentials))
+}
+
+func (h *AuthHandler) DeleteWebAuthnCredential(c *gin.Context) {
+	credentialID, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(c.Param(web.IDParamKey), "="))
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	err = h.authService.DeleteWebAuthnCredential(c.Request.Context(), web.GetUserID(c), credentialID, deviceInfo(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrWebAuthnCredentialUnknown) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Passkey removed")
+}
+
+func webAuthnFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWebAuthnDisabled):
+		response.NotFound(c, err)
+	case errors.Is(err, domain.ErrInvalidWebAuthnChallenge), errors.Is(err, domain.ErrInvalidWebAuthnResponse):
+		response.BadRequest(c, err.Error(), err)
+	case errors.Is(err, domain.ErrWebAuthnCredentialExists):
+		response.Conflict(c, err.Error(), err)
+	case errors.Is(err, domain.ErrWebAuthnCredentialUnknown), errors.Is(err, domain.ErrWebAuthnSignCount):
+		response.Unauthorized(c, err)
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// UserFilter narrows the admin user listing. Query matches the email or
+// display name, case-insensitively.
+type UserFilter struct {
+	Query    string
+	Role     *Role
+	Disabled *bool
+}
+
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+func (u *User) Disable(reason string) {
+	now := time.Now()
+	u.DisabledAt = &now
+	u.DisabledReason = reason
+	u.UpdatedAt = now
+}
+
+func (u *User) Enable() {
+	u.DisabledAt = nil
+	u.DisabledReason = ""
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) ChangeRole(role Role) {
+	u.Role = role
+	u.UpdatedAt = time.Now()
+}
+
+// RequirePasswordReset blocks sign-in by any method until the user sets a new
+// password through the reset flow.
+func (u *User) RequirePasswordReset() {
+	u.PasswordResetRequired = true
+	u.UpdatedAt = time.Now()
+}
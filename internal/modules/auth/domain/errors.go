@@ -3,7 +3,36 @@ package domain
 import "errors"
 
 var (
-	ErrUserAlreadyExists = errors.New("user with this email already exists")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrInvalidPassword   = errors.New("invalid password")
+	ErrUserAlreadyExists   = errors.New("user with this email already exists")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidPassword     = errors.New("invalid password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidActionToken  = errors.New("invalid or expired token")
+	ErrEmailNotVerified    = errors.New("email not verified")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrAccountLocked       = errors.New("too many failed sign-in attempts")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFASetupNotStarted  = errors.New("two-factor setup has not been started")
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrUnknownProvider     = errors.New("unknown identity provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired login state")
+	ErrOIDCEmailUnverified = errors.New("identity provider did not verify the email")
+	ErrEmailUnchanged      = errors.New("new email is the same as the current one")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrAccountDisabled     = errors.New("account is disabled")
+	ErrPasswordResetNeeded = errors.New("password reset required")
+	ErrCannotModifySelf    = errors.New("admins cannot change their own account this way")
+	ErrWeakPassword        = errors.New("password does not meet the policy")
+	ErrCannotImpersonate   = errors.New("only customer accounts can be impersonated")
+	ErrNotImpersonating    = errors.New("the current session is not an impersonation")
+
+	ErrInvalidWebAuthnChallenge  = errors.New("invalid or expired passkey challenge")
+	ErrInvalidWebAuthnResponse   = errors.New("invalid passkey response")
+	ErrWebAuthnCredentialExists  = errors.New("passkey is already registered")
+	ErrWebAuthnCredentialUnknown = errors.New("unknown passkey")
+	ErrWebAuthnSignCount         = errors.New("passkey signature counter did not advance")
+	ErrWebAuthnDisabled          = errors.New("passkeys are not configured")
 )
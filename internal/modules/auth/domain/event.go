@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuthEventType string
+
+const (
+	EventSignIn             AuthEventType = "sign_in"
+	EventSignOut            AuthEventType = "sign_out"
+	EventTokenReuse         AuthEventType = "refresh_token_reuse"
+	EventPasswordChange     AuthEventType = "password_change"
+	EventPasswordReset      AuthEventType = "password_reset"
+	EventEmailChange        AuthEventType = "email_change"
+	EventMFAEnabled         AuthEventType = "mfa_enabled"
+	EventMFADisabled        AuthEventType = "mfa_disabled"
+	EventRecoveryCodesReset AuthEventType = "recovery_codes_regenerated"
+	EventRoleChange         AuthEventType = "role_change"
+	EventAccountDisable     AuthEventType = "account_disabled"
+	EventAccountEnable      AuthEventType = "account_enabled"
+	EventAccountUnlock      AuthEventType = "account_unlocked"
+	EventPasswordResetForce AuthEventType = "password_reset_forced"
+	EventImpersonationStart AuthEventType = "impersonation_start"
+	EventImpersonationStop  AuthEventType = "impersonation_stop"
+	EventPasskeyRegistered  AuthEventType = "passkey_registered"
+	EventPasskeyRemoved     AuthEventType = "passkey_removed"
+)
+
+type AuthEventOutcome string
+
+const (
+	OutcomeSuccess AuthEventOutcome = "success"
+	OutcomeFailure AuthEventOutcome = "failure"
+	// OutcomeMFARequired marks a correct password still awaiting a second factor.
+	OutcomeMFARequired AuthEventOutcome = "mfa_required"
+)
+
+// AuthEvent is one entry in the append-only authentication audit log, which
+// also records every change an admin makes to an account. UserID is nil when
+// a failed sign-in names an unknown email; ActorID is set when an admin acted
+// on someone else's account.
+type AuthEvent struct {
+	ID        uuid.UUID        `bson:"_id"`
+	Type      AuthEventType    `bson:"type"`
+	Outcome   AuthEventOutcome `bson:"outcome"`
+	Reason    string           `bson:"reason,omitempty"`
+	Method    string           `bson:"method,omitempty"`
+	UserID    *uuid.UUID       `bson:"user_id,omitempty"`
+	ActorID   *uuid.UUID       `bson:"actor_id,omitempty"`
+	Email     string           `bson:"email,omitempty"`
+	IP        string           `bson:"ip,omitempty"`
+	UserAgent string           `bson:"user_agent,omitempty"`
+	CreatedAt time.Time        `bson:"created_at"`
+}
+
+type AuthEventFilter struct {
+	UserID *uuid.UUID
+	Type   AuthEventType
+	From   *time.Time
+	To     *time.Time
+	// AdminOnly keeps the events an admin caused.
+	AdminOnly bool
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Impersonation is a short-lived access token for acting as a customer. It
+// comes without a refresh token, so it cannot outlive ExpiresAt.
+type Impersonation struct {
+	AccessToken string
+	SessionID   uuid.UUID
+	UserID      uuid.UUID
+	ExpiresAt   time.Time
+}
+
+func (u *User) CanBeImpersonated() bool {
+	return u.Role == UserRole && !u.IsDisabled()
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LockoutError is returned while sign-in is locked for an account or IP.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrAccountLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockoutError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+func AccountAttemptKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func IPAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// LockoutDuration doubles the base duration for every failure past the
+// threshold, capped at limit. A threshold of 0 never locks.
+func LockoutDuration(failures, threshold int64, base, limit time.Duration) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	d := base
+	for i := threshold; i < failures && d < limit; i++ {
+		d *= 2
+	}
+	return min(d, limit)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int64
+		threshold int64
+		want      time.Duration
+	}{
+		{name: "below threshold", failures: 4, threshold: 5, want: 0},
+		{name: "at threshold", failures: 5, threshold: 5, want: time.Minute},
+		{name: "doubles past threshold", failures: 7, threshold: 5, want: 4 * time.Minute},
+		{name: "capped at limit", failures: 50, threshold: 5, want: time.Hour},
+		{name: "zero threshold disables", failures: 100, threshold: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LockoutDuration(tt.failures, tt.threshold, time.Minute, time.Hour)
+			if got != tt.want {
+				t.Errorf("LockoutDuration(%d, %d) = %s, want %s", tt.failures, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"strings"
+)
+
+// NewMagicLinkNonce returns the value kept in the requesting browser's cookie.
+func NewMagicLinkNonce() (string, error) {
+	return randomToken()
+}
+
+// MagicLinkKey derives the key a magic link is signed with from the browser
+// nonce, so the link fails verification in any browser without that cookie.
+func MagicLinkKey(secret []byte, nonce string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(PurposeMagicLink))
+	mac.Write([]byte("."))
+	mac.Write([]byte(nonce))
+	return mac.Sum(nil)
+}
+
+func MagicLinkAttemptKey(email string) string {
+	return "magic_link:" + strings.ToLower(email)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"time"
+
+	"bobshop/pkg/auth"
+)
+
+const recoveryCodeCount = 10
+
+func (u *User) HasMFA() bool {
+	return u.MFAEnabledAt != nil
+}
+
+// BeginTOTPSetup stores a new secret that only takes effect once the user
+// proves their authenticator produces matching codes.
+func (u *User) BeginTOTPSetup() (string, error) {
+	if u.HasMFA() {
+		return "", ErrMFAAlreadyEnabled
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	u.TOTPPendingSecret = secret
+	u.UpdatedAt = time.Now()
+	return secret, nil
+}
+
+// ConfirmTOTP enables two-factor authentication and returns fresh recovery
+// codes in plain text. key signs the stored recovery code digests.
+func (u *User) ConfirmTOTP(code string, key []byte) ([]string, error) {
+	if u.HasMFA() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if u.TOTPPendingSecret == "" {
+		return nil, ErrMFASetupNotStarted
+	}
+	step, ok := auth.ValidateTOTP(u.TOTPPendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	u.TOTPSecret = u.TOTPPendingSecret
+	u.TOTPPendingSecret = ""
+	u.TOTPLastStep = step
+	u.RecoveryCodeHashes = hashes
+	u.MFAEnabledAt = &now
+	u.UpdatedAt = now
+	return codes, nil
+}
+
+// VerifySecondFactor accepts a current TOTP code or an unused recovery code.
+// Each TOTP step and each recovery code works only once.
+func (u *User) VerifySecondFactor(code string, key []byte) bool {
+	if !u.HasMFA() {
+		return false
+	}
+	if step, ok := auth.ValidateTOTP(u.TOTPSecret, code, time.Now()); ok && step > u.TOTPLastStep {
+		u.TOTPLastStep = step
+		u.UpdatedAt = time.Now()
+		return true
+	}
+
+	// Every stored digest is compared so the time taken does not reveal
+	// which code matched.
+	match := -1
+	for i, hash := range u.RecoveryCodeHashes {
+		if auth.CompareRecoveryCode(hash, code, key) {
+			match = i
+		}
+	}
+	if match < 0 {
+		return false
+	}
+	u.RecoveryCodeHashes = append(u.RecoveryCodeHashes[:match], u.RecoveryCodeHashes[match+1:]...)
+	u.UpdatedAt = time.Now()
+	return true
+}
+
+// DisableMFA turns two-factor authentication off and forgets the secret and
+// recovery codes. The caller checks a second factor first.
+func (u *User) DisableMFA() error {
+	if !u.HasMFA() {
+		return ErrMFANotEnabled
+	}
+	u.TOTPSecret = ""
+	u.TOTPPendingSecret = ""
+	u.TOTPLastStep = 0
+	u.RecoveryCodeHashes = nil
+	u.MFAEnabledAt = nil
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code and returns the new
+// ones in plain text.
+func (u *User) RegenerateRecoveryCodes(key []byte) ([]string, error) {
+	if !u.HasMFA() {
+		return nil, ErrMFANotEnabled
+	}
+	codes, hashes, err := newRecoveryCodes(key)
+	if err != nil {
+		return nil, err
+	}
+	u.RecoveryCodeHashes = hashes
+	u.UpdatedAt = time.Now()
+	return codes, nil
+}
+
+func newRecoveryCodes(key []byte) ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = auth.HashRecoveryCode(c, key)
+	}
+	return codes, hashes, nil
+}
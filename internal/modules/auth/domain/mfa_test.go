@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"bobshop/pkg/auth"
+)
+
+var testMFAKey = []byte("test-token-secret")
+
+func enrolledUser(t *testing.T) (*User, []string) {
+	t.Helper()
+	user := &User{}
+	secret, err := user.BeginTOTPSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := user.ConfirmTOTP(code, testMFAKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, codes
+}
+
+func TestRecoveryCodesStoredAsDigests(t *testing.T) {
+	user, codes := enrolledUser(t)
+	if len(user.RecoveryCodeHashes) != len(codes) {
+		t.Fatalf("stored %d digests for %d codes", len(user.RecoveryCodeHashes), len(codes))
+	}
+	for _, code := range codes {
+		if slices.Contains(user.RecoveryCodeHashes, code) {
+			t.Errorf("recovery code %q stored in plain text", code)
+		}
+	}
+}
+
+func TestVerifySecondFactorRecoveryCode(t *testing.T) {
+	user, codes := enrolledUser(t)
+
+	tests := []struct {
+		name string
+		code string
+		key  []byte
+		want bool
+	}{
+		{name: "unknown code", code: "aaaa-aaaa", key: testMFAKey, want: false},
+		{name: "wrong key", code: codes[0], key: []byte("other"), want: false},
+		{name: "valid code", code: codes[0], key: testMFAKey, want: true},
+		{name: "code used twice", code: codes[0], key: testMFAKey, want: false},
+		{name: "unformatted code", code: strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")), key: testMFAKey, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := user.VerifySecondFactor(tt.code, tt.key); got != tt.want {
+				t.Errorf("VerifySecondFactor(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+	if got, want := len(user.RecoveryCodeHashes), len(codes)-2; got != want {
+		t.Errorf("%d recovery codes left, want %d", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+)
+
+// Identity links a user to an account at an external OpenID provider.
+type Identity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"subject"`
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
+
+// ExternalIdentity is what a provider asserted in a verified ID token.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// OIDCAuthRequest is the per-login state kept between redirecting the browser
+// to the provider and handling its callback.
+type OIDCAuthRequest struct {
+	Provider     string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+func NewOIDCAuthRequest(provider string) (*OIDCAuthRequest, error) {
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCAuthRequest{
+		Provider:     provider,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// CodeChallenge is the PKCE S256 challenge for the verifier.
+func (r *OIDCAuthRequest) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(r.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type OIDCClient interface {
+	AuthCodeURL(ctx context.Context, req *OIDCAuthRequest) (string, error)
+	// Exchange redeems the authorization code and returns the identity from
+	// the ID token after checking its signature, issuer, audience and nonce.
+	Exchange(ctx context.Context, req *OIDCAuthRequest, code string) (*ExternalIdentity, error)
+}
+
+type OIDCStateStore interface {
+	Save(ctx context.Context, req *OIDCAuthRequest, ttl time.Duration) error
+	Consume(ctx context.Context, state string) (*OIDCAuthRequest, error)
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	PasswordRuleMinLength     = "min_length"
+	PasswordRuleMaxLength     = "max_length"
+	PasswordRuleUppercase     = "uppercase"
+	PasswordRuleLowercase     = "lowercase"
+	PasswordRuleDigit         = "digit"
+	PasswordRuleSymbol        = "symbol"
+	PasswordRuleContainsEmail = "contains_email"
+	PasswordRuleBreached      = "breached"
+)
+
+// PasswordPolicy lengths count characters, except MaxBytes, which caps the
+// UTF-8 encoded size for hashes that ignore anything past it; 0 means no cap.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	DisallowEmail bool
+}
+
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a password broke. It matches
+// ErrWeakPassword with errors.Is.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// BreachedPasswordChecker reports whether a password appears in a corpus of
+// leaked passwords.
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+// Check returns the rules the password breaks, or nil if it passes.
+func (p PasswordPolicy) Check(password, email string) []PasswordViolation {
+	var violations []PasswordViolation
+	add := func(rule, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		add(PasswordRuleMinLength, "must be at least "+strconv.Itoa(p.MinLength)+" characters")
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(PasswordRuleMaxLength, "must be at most "+strconv.Itoa(p.MaxLength)+" characters")
+	} else if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		add(PasswordRuleMaxLength, "must be at most "+strconv.Itoa(p.MaxBytes)+" bytes; accented letters and symbols take several")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		add(PasswordRuleUppercase, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		add(PasswordRuleLowercase, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add(PasswordRuleDigit, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add(PasswordRuleSymbol, "must contain a symbol")
+	}
+
+	if p.DisallowEmail && containsEmail(password, email) {
+		add(PasswordRuleContainsEmail, "must not contain your email address")
+	}
+	return violations
+}
+
+// containsEmail catches the full address and its local part, ignoring case.
+// Local parts shorter than four characters are too common to reject.
+func containsEmail(password, email string) bool {
+	if email == "" {
+		return false
+	}
+	password = strings.ToLower(password)
+	email = strings.ToLower(email)
+	if strings.Contains(password, email) {
+		return true
+	}
+	local, _, _ := strings.Cut(email, "@")
+	return len(local) >= 4 && strings.Contains(password, local)
+}
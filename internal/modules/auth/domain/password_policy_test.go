@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	bcrypt := PasswordPolicy{MinLength: 8, MaxLength: 128, MaxBytes: 72}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		email    string
+		want     []string
+	}{
+		{name: "passes", policy: bcrypt, password: "correct horse battery"},
+		{name: "too short", policy: bcrypt, password: "short", want: []string{PasswordRuleMinLength}},
+		{name: "too many characters", policy: bcrypt, password: strings.Repeat("a", 129), want: []string{PasswordRuleMaxLength}},
+		{name: "72 ascii bytes", policy: bcrypt, password: strings.Repeat("a", 72)},
+		{name: "73 ascii bytes", policy: bcrypt, password: strings.Repeat("a", 73), want: []string{PasswordRuleMaxLength}},
+		// 40 characters, but "ệ" is three bytes in UTF-8.
+		{name: "multibyte over byte cap", policy: bcrypt, password: strings.Repeat("ệ", 40), want: []string{PasswordRuleMaxLength}},
+		{name: "multibyte without byte cap", policy: PasswordPolicy{MinLength: 8, MaxLength: 128}, password: strings.Repeat("ệ", 40)},
+		{
+			name:     "contains email",
+			policy:   PasswordPolicy{MinLength: 8, DisallowEmail: true},
+			password: "Shopper-2024!",
+			email:    "shopper@example.com",
+			want:     []string{PasswordRuleContainsEmail},
+		},
+		{
+			name:     "missing classes",
+			policy:   PasswordPolicy{MinLength: 8, RequireUpper: true, RequireDigit: true, RequireSymbol: true},
+			password: "lowercaseonly",
+			want:     []string{PasswordRuleUppercase, PasswordRuleDigit, PasswordRuleSymbol},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range tt.policy.Check(tt.password, tt.email) {
+				got = append(got, v.Rule)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
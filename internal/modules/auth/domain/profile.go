@@ -0,0 +1,83 @@
+package domain
+
+import "time"
+
+type Profile struct {
+	DisplayName        string     `bson:"display_name,omitempty" json:"display_name"`
+	Phone              string     `bson:"phone,omitempty" json:"phone"`
+	AvatarURL          string     `bson:"avatar_url,omitempty" json:"avatar_url"`
+	Locale             string     `bson:"locale,omitempty" json:"locale"`
+	MarketingConsent   bool       `bson:"marketing_consent" json:"marketing_consent"`
+	MarketingConsentAt *time.Time `bson:"marketing_consent_at,omitempty" json:"marketing_consent_at"`
+}
+
+// ProfileUpdate holds the fields a PATCH changes; nil fields are left alone.
+type ProfileUpdate struct {
+	DisplayName      *string
+	Phone            *string
+	AvatarURL        *string
+	Locale           *string
+	MarketingConsent *bool
+}
+
+// EmailChange is a requested new address awaiting confirmation from its
+// mailbox. Only the token issued with the latest request can confirm it.
+type EmailChange struct {
+	Email       string    `bson:"email"`
+	TokenHash   string    `bson:"token_hash"`
+	RequestedAt time.Time `bson:"requested_at"`
+}
+
+func (u *User) UpdateProfile(update ProfileUpdate) {
+	if update.DisplayName != nil {
+		u.Profile.DisplayName = *update.DisplayName
+	}
+	if update.Phone != nil {
+		u.Profile.Phone = *update.Phone
+	}
+	if update.AvatarURL != nil {
+		u.Profile.AvatarURL = *update.AvatarURL
+	}
+	if update.Locale != nil {
+		u.Profile.Locale = *update.Locale
+	}
+	// The consent time is recorded as proof of when the user opted in.
+	if update.MarketingConsent != nil && *update.MarketingConsent != u.Profile.MarketingConsent {
+		u.Profile.MarketingConsent = *update.MarketingConsent
+		if u.Profile.MarketingConsent {
+			now := time.Now()
+			u.Profile.MarketingConsentAt = &now
+		} else {
+			u.Profile.MarketingConsentAt = nil
+		}
+	}
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) RequestEmailChange(email, tokenHash string) error {
+	email = NormalizeEmail(email)
+	if email == NormalizeEmail(u.Email) {
+		return ErrEmailUnchanged
+	}
+	u.EmailChange = &EmailChange{
+		Email:       email,
+		TokenHash:   tokenHash,
+		RequestedAt: time.Now(),
+	}
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// ConfirmEmailChange switches to the pending address, which the confirmation
+// link has just proven the user owns, and returns the previous one.
+func (u *User) ConfirmEmailChange(tokenHash string) (string, error) {
+	if u.EmailChange == nil || u.EmailChange.TokenHash != tokenHash {
+		return "", ErrInvalidActionToken
+	}
+	previous := u.Email
+	u.Email = u.EmailChange.Email
+	u.EmailChange = nil
+	u.MarkVerified()
+	u.UpdatedAt = time.Now()
+	return previous, nil
+}
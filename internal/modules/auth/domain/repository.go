@@ -2,9 +2,69 @@ package domain
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type AuthRepository interface {
 	Create(ctx context.Context, user *User) error
 	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*User, error)
+	FindByIdentity(ctx context.Context, provider, subject string) (*User, error)
+	FindByWebAuthnCredential(ctx context.Context, credentialID []byte) (*User, error)
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List returns up to limit users after the cursor, newest first, and the
+	// cursor for the following page, or nil on the last page.
+	List(ctx context.Context, filter UserFilter, cursor *uuid.UUID, limit int) ([]*User, *uuid.UUID, error)
+}
+
+// AuthEventRepository is the append-only authentication audit log.
+type AuthEventRepository interface {
+	Record(ctx context.Context, event *AuthEvent) error
+	// List returns events newest first, paged like AuthRepository.List.
+	List(ctx context.Context, filter AuthEventFilter, cursor *uuid.UUID, limit int) ([]*AuthEvent, *uuid.UUID, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*AuthEvent, error)
+	// Anonymize strips the personal data from the user's events and from
+	// failed sign-ins naming their email, keeping what happened and when.
+	Anonymize(ctx context.Context, userID uuid.UUID, email string) error
+}
+
+type RefreshTokenStore interface {
+	Save(ctx context.Context, token *RefreshToken) error
+	// Consume marks the token as used and returns it. A token that was already
+	// consumed is returned together with ErrRefreshTokenReused.
+	Consume(ctx context.Context, hash string) (*RefreshToken, error)
+}
+
+type ActionTokenStore interface {
+	Save(ctx context.Context, token *ActionToken) error
+	// Consume deletes the token and returns the user it was issued to, so each
+	// token works exactly once.
+	Consume(ctx context.Context, purpose TokenPurpose, hash string) (uuid.UUID, error)
+	// Peek returns the user a token was issued to without using it up.
+	Peek(ctx context.Context, purpose TokenPurpose, hash string) (uuid.UUID, error)
+	// RevokeAll deletes every outstanding token of a purpose issued to a user.
+	RevokeAll(ctx context.Context, purpose TokenPurpose, userID uuid.UUID) error
+}
+
+// LoginAttemptStore counts failed sign-ins per key (an account or an IP) and
+// holds temporary locks.
+type LoginAttemptStore interface {
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	// LockedFor returns how long the key stays locked, or zero.
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type SessionStore interface {
+	Create(ctx context.Context, session *Session, ttl time.Duration) error
+	Get(ctx context.Context, id uuid.UUID) (*Session, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	// Extend records activity on the session and pushes its expiry out by ttl.
+	Extend(ctx context.Context, id uuid.UUID, ttl time.Duration) error
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
 }
@@ -1,5 +1,7 @@
 package domain
 
+import "bobshop/internal/platform/security"
+
 type Role int
 
 const (
@@ -7,8 +9,17 @@ const (
 	AdminRole
 )
 
-var roleNames = []string{"user", "admin"}
+var roleNames = []string{security.RoleUser, security.RoleAdmin}
 
 func (r Role) String() string {
 	return roleNames[r]
 }
+
+func ParseRole(name string) (Role, error) {
+	for i, roleName := range roleNames {
+		if roleName == name {
+			return Role(i), nil
+		}
+	}
+	return 0, ErrInvalidRole
+}
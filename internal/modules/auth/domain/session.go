@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceInfo describes the client a session was opened from.
+type DeviceInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is one signed-in device. Its ID is carried in the sid claim of every
+// access token and shared by the refresh tokens rotated from the same sign-in.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	MFA        bool      `json:"mfa"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// ImpersonatorID is the admin who opened this session as the user.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+}
+
+func NewSession(userID uuid.UUID, device DeviceInfo, mfa bool) (*Session, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  device.UserAgent,
+		IP:         device.IP,
+		MFA:        mfa,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const randomTokenBytes = 32
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// SignInResult carries either the tokens of a new session or, when the user
+// has two-factor authentication enabled, a short-lived MFA token to exchange
+// for them once the second factor is verified.
+type SignInResult struct {
+	Tokens   *AuthTokens
+	MFAToken string
+}
+
+// RefreshToken is the server-side record of an issued refresh token. Only the
+// hash of the token is stored; the plain value is handed to the client once.
+// Tokens rotated from the same sign-in belong to one session, which is revoked
+// as a whole when a used token is presented again.
+type RefreshToken struct {
+	Hash      string
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+func NewRefreshToken(sessionID, userID uuid.UUID, ttl time.Duration) (*RefreshToken, string, error) {
+	plain, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := &RefreshToken{
+		Hash:      HashRefreshToken(plain),
+		SessionID: sessionID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	return token, plain, nil
+}
+
+func HashRefreshToken(plain string) string {
+	return hashToken(plain)
+}
+
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, randomTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
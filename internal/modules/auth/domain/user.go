@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,30 +10,77 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID `bson:"_id"`
-	Email        string    `bson:"email"`
-	PasswordHash string    `bson:"password_hash"`
-	Role         Role      `bson:"role"`
-	CreatedAt    time.Time `bson:"created_at"`
-	UpdatedAt    time.Time `bson:"updated_at"`
+	ID           uuid.UUID  `bson:"_id"`
+	Email        string     `bson:"email"`
+	PasswordHash string     `bson:"password_hash"`
+	Role         Role       `bson:"role"`
+	VerifiedAt   *time.Time `bson:"verified_at"`
+	CreatedAt    time.Time  `bson:"created_at"`
+	UpdatedAt    time.Time  `bson:"updated_at"`
+
+	Profile     Profile      `bson:"profile"`
+	EmailChange *EmailChange `bson:"email_change,omitempty"`
+
+	// Admin controls
+	DisabledAt            *time.Time `bson:"disabled_at"`
+	DisabledReason        string     `bson:"disabled_reason,omitempty"`
+	PasswordResetRequired bool       `bson:"password_reset_required,omitempty"`
+
+	// Two-factor authentication
+	MFAEnabledAt       *time.Time `bson:"mfa_enabled_at"`
+	TOTPSecret         string     `bson:"totp_secret,omitempty"`
+	TOTPPendingSecret  string     `bson:"totp_pending_secret,omitempty"`
+	TOTPLastStep       int64      `bson:"totp_last_step,omitempty"`
+	RecoveryCodeHashes []string   `bson:"recovery_code_hashes,omitempty"`
+
+	// External OpenID Connect accounts
+	Identities []Identity `bson:"identities,omitempty"`
+
+	// Passkeys
+	WebAuthnCredentials []WebAuthnCredential `bson:"webauthn_credentials,omitempty"`
 }
 
 func NewUser(email, password string) (*User, error) {
+	user, err := newUser(email)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// NewExternalUser creates a user without a password for someone signing in
+// through an OpenID provider that vouched for their email.
+func NewExternalUser(identity *ExternalIdentity) (*User, error) {
+	user, err := newUser(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	user.MarkVerified()
+	user.LinkIdentity(identity)
+	return user, nil
+}
+
+// NormalizeEmail is the form emails are stored and looked up in, so addresses
+// differing only in case or surrounding space name the same account.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func newUser(email string) (*User, error) {
 	id, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
 	}
-	user := &User{
+	return &User{
 		ID:        id,
-		Email:     email,
+		Email:     NormalizeEmail(email),
 		Role:      UserRole,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	}
-	if err := user.SetPassword(password); err != nil {
-		return nil, err
-	}
-	return user, nil
+	}, nil
 }
 
 func (u *User) SetPassword(password string) error {
@@ -41,10 +89,44 @@ func (u *User) SetPassword(password string) error {
 		return err
 	}
 	u.PasswordHash = hashedPassword
+	u.PasswordResetRequired = false
 	u.UpdatedAt = time.Now()
 	return nil
 }
 
 func (u *User) CheckPassword(password string) bool {
+	if u.PasswordHash == "" {
+		return false
+	}
 	return auth.ComparePassword(u.PasswordHash, password)
 }
+
+// PasswordNeedsRehash reports whether the stored hash is outdated and should be
+// replaced the next time the plain password is known.
+func (u *User) PasswordNeedsRehash() bool {
+	return u.PasswordHash != "" && auth.NeedsRehash(u.PasswordHash)
+}
+
+func (u *User) LinkIdentity(identity *ExternalIdentity) {
+	for _, existing := range u.Identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return
+		}
+	}
+	u.Identities = append(u.Identities, Identity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		LinkedAt: time.Now(),
+	})
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+func (u *User) MarkVerified() {
+	now := time.Now()
+	u.VerifiedAt = &now
+	u.UpdatedAt = now
+}
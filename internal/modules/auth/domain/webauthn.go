@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebAuthnCeremony string
+
+const (
+	CeremonyRegistration WebAuthnCeremony = "registration"
+	CeremonyAssertion    WebAuthnCeremony = "assertion"
+)
+
+// WebAuthnChallenge is the server side of one registration or sign-in
+// ceremony. The browser signs over the challenge, which is how the response
+// is matched back to it. Registration is tied to the signed-in user.
+type WebAuthnChallenge struct {
+	Challenge string           `json:"challenge"`
+	Ceremony  WebAuthnCeremony `json:"ceremony"`
+	UserID    *uuid.UUID       `json:"user_id,omitempty"`
+}
+
+func NewWebAuthnChallenge(ceremony WebAuthnCeremony, userID *uuid.UUID) (*WebAuthnChallenge, error) {
+	challenge, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnChallenge{Challenge: challenge, Ceremony: ceremony, UserID: userID}, nil
+}
+
+// WebAuthnOptions is what the browser needs to run a ceremony. User and
+// ExcludeCredentials are only set for registration.
+type WebAuthnOptions struct {
+	Challenge          string
+	RPID               string
+	RPName             string
+	UserVerification   string
+	Timeout            time.Duration
+	User               *User
+	ExcludeCredentials [][]byte
+}
+
+// WebAuthnAssertion is the browser's answer to a sign-in challenge.
+type WebAuthnAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
+
+type WebAuthnChallengeStore interface {
+	Save(ctx context.Context, challenge *WebAuthnChallenge, ttl time.Duration) error
+	Consume(ctx context.Context, challenge string) (*WebAuthnChallenge, error)
+}
+
+// WebAuthnCredential is a registered passkey. PublicKey is PKIX DER.
+type WebAuthnCredential struct {
+	ID         []byte     `bson:"id" json:"id"`
+	PublicKey  []byte     `bson:"public_key" json:"-"`
+	Algorithm  int64      `bson:"algorithm" json:"algorithm"`
+	SignCount  uint32     `bson:"sign_count" json:"sign_count"`
+	AAGUID     []byte     `bson:"aaguid,omitempty" json:"-"`
+	Name       string     `bson:"name" json:"name"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+}
+
+// RecordUse moves the signature counter forward. A counter that does not
+// advance means the authenticator may have been cloned; authenticators that
+// keep no counter always report zero and are let through.
+func (c *WebAuthnCredential) RecordUse(signCount uint32) error {
+	if (signCount != 0 || c.SignCount != 0) && signCount <= c.SignCount {
+		return ErrWebAuthnSignCount
+	}
+	now := time.Now()
+	c.SignCount = signCount
+	c.LastUsedAt = &now
+	return nil
+}
+
+func (u *User) WebAuthnCredential(id []byte) *WebAuthnCredential {
+	for i := range u.WebAuthnCredentials {
+		if bytes.Equal(u.WebAuthnCredentials[i].ID, id) {
+			return &u.WebAuthnCredentials[i]
+		}
+	}
+	return nil
+}
+
+func (u *User) AddWebAuthnCredential(credential WebAuthnCredential) {
+	u.WebAuthnCredentials = append(u.WebAuthnCredentials, credential)
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) RemoveWebAuthnCredential(id []byte) bool {
+	n := len(u.WebAuthnCredentials)
+	u.WebAuthnCredentials = slices.DeleteFunc(u.WebAuthnCredentials, func(c WebAuthnCredential) bool {
+		return bytes.Equal(c.ID, id)
+	})
+	if len(u.WebAuthnCredentials) == n {
+		return false
+	}
+	u.UpdatedAt = time.Now()
+	return true
+}
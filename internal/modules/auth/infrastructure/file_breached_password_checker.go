@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bobshop/internal/platform/config"
+)
+
+const breachedPrefixLength = 5
+
+// FileBreachedPasswordChecker looks passwords up in a local copy of a
+// k-anonymity SHA-1 corpus: one file per five character hash prefix, named
+// <PREFIX>.txt, holding "SUFFIX:COUNT" lines, as produced by the Pwned
+// Passwords range downloader. Only the file for the password's prefix is read.
+// Without a configured directory every password passes.
+type FileBreachedPasswordChecker struct {
+	dir string
+}
+
+func NewFileBreachedPasswordChecker(cfg *config.AuthConfig) *FileBreachedPasswordChecker {
+	return &FileBreachedPasswordChecker{dir: cfg.PasswordPolicy.BreachedPasswordsDir}
+}
+
+func (f *FileBreachedPasswordChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	if f.dir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	file, err := os.Open(filepath.Join(f.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,147 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/database"
+)
+
+const (
+	usersEmailIndex      = "users_email"
+	authEventsCollection = "auth_events"
+	// legacyAuthEventTTLIndex pruned every event by created_at, admin
+	// actions included.
+	legacyAuthEventTTLIndex = "created_at_ttl"
+	indexNotFoundErr        = 27
+)
+
+// Migrations are run by cmd/migrate. Some depend on the configured retention.
+func Migrations(cfg *config.AuthConfig) []database.Migration {
+	return []database.Migration{
+		{
+			ID:          "20261018_users_email",
+			Description: "store user emails normalized and index them uniquely",
+			Up:          normalizeUserEmails,
+		},
+		{
+			ID:          "20261018_auth_events_expiry",
+			Description: "expire auth events by expires_at and keep admin actions apart",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return expireAuthEvents(ctx, db, cfg)
+			},
+		},
+	}
+}
+
+type userEmail struct {
+	ID    uuid.UUID `bson:"_id"`
+	Email string    `bson:"email"`
+}
+
+// normalizeUserEmails rewrites emails stored before FindByEmail normalized
+// its input, which would otherwise no longer be found. Accounts whose emails
+// only differ in case cannot both keep theirs, so it stops and lists them for
+// an operator to merge or rename before anything is changed.
+func normalizeUserEmails(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	cursor, err := users.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return err
+	}
+	var all []userEmail
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+
+	owners := make(map[string][]string, len(all))
+	var updates []mongo.WriteModel
+	for _, user := range all {
+		email := domain.NormalizeEmail(user.Email)
+		owners[email] = append(owners[email], user.ID.String())
+		if email != user.Email {
+			updates = append(updates, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": user.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"email": email}}))
+		}
+	}
+
+	var clashes []string
+	for _, ids := range owners {
+		if len(ids) > 1 {
+			clashes = append(clashes, strings.Join(ids, " and "))
+		}
+	}
+	if len(clashes) > 0 {
+		return fmt.Errorf("accounts share an email once normalized: %s", strings.Join(clashes, "; "))
+	}
+
+	if len(updates) > 0 {
+		if _, err := users.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName(usersEmailIndex).SetUnique(true),
+	})
+	return err
+}
+
+// expireAuthEvents replaces the created_at TTL index, which also pruned admin
+// actions, with one on expires_at. Events recorded before it get the expiry
+// their retention gives them; admin actions keep none unless one is set.
+func expireAuthEvents(ctx context.Context, db *mongo.Database, cfg *config.AuthConfig) error {
+	retention, adminRetention, err := auditRetentions(cfg)
+	if err != nil {
+		return err
+	}
+	events := db.Collection(authEventsCollection)
+
+	_, err = events.Indexes().DropOne(ctx, legacyAuthEventTTLIndex)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Code == indexNotFoundErr) {
+		return err
+	}
+
+	backfill := []struct {
+		actor     bool
+		retention time.Duration
+	}{
+		{actor: false, retention: retention},
+		{actor: true, retention: adminRetention},
+	}
+	for _, b := range backfill {
+		if b.retention == 0 {
+			continue
+		}
+		_, err := events.UpdateMany(ctx,
+			bson.M{"actor_id": bson.M{"$exists": b.actor}, "expires_at": bson.M{"$exists": false}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"expires_at": bson.M{"$add": bson.A{"$created_at", b.retention.Milliseconds()}},
+			}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = events.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
@@ -0,0 +1,176 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+)
+
+const defaultAuditRetention = 90 * 24 * time.Hour
+
+// MongoAuthEventRepository stores auth events, each with the time Mongo may
+// prune it through the TTL index on expires_at. Admin actions have their own
+// retention and, by default, no expiry at all. Events are never updated.
+type MongoAuthEventRepository struct {
+	collection     *mongo.Collection
+	retention      time.Duration
+	adminRetention time.Duration
+}
+
+func NewMongoAuthEventRepository(db *mongo.Database, cfg *config.AuthConfig) (*MongoAuthEventRepository, error) {
+	retention, adminRetention, err := auditRetentions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &MongoAuthEventRepository{
+		collection:     db.Collection(authEventsCollection),
+		retention:      retention,
+		adminRetention: adminRetention,
+	}, nil
+}
+
+// auditRetentions parses the configured retentions. An admin retention of
+// zero means admin actions never expire.
+func auditRetentions(cfg *config.AuthConfig) (retention, adminRetention time.Duration, err error) {
+	retention = defaultAuditRetention
+	if cfg.AuditRetention != "" {
+		if retention, err = time.ParseDuration(cfg.AuditRetention); err != nil {
+			return 0, 0, err
+		}
+	}
+	if cfg.AdminAuditRetention != "" {
+		if adminRetention, err = time.ParseDuration(cfg.AdminAuditRetention); err != nil {
+			return 0, 0, err
+		}
+		if adminRetention <= 0 {
+			return 0, 0, fmt.Errorf("admin audit retention %s must be positive, or unset to keep admin actions", adminRetention)
+		}
+	}
+	if retention <= 0 {
+		return 0, 0, fmt.Errorf("audit retention %s must be positive", retention)
+	}
+	return retention, adminRetention, nil
+}
+
+// authEventDocument is an event as stored, with the time it expires.
+type authEventDocument struct {
+	domain.AuthEvent `bson:",inline"`
+	ExpiresAt        *time.Time `bson:"expires_at,omitempty"`
+}
+
+// expiresAt returns when the event may be pruned, or nil to keep it.
+func (r *MongoAuthEventRepository) expiresAt(event *domain.AuthEvent) *time.Time {
+	retention := r.retention
+	if event.ActorID != nil {
+		retention = r.adminRetention
+	}
+	if retention == 0 {
+		return nil
+	}
+	at := event.CreatedAt.Add(retention)
+	return &at
+}
+
+func (r *MongoAuthEventRepository) Record(ctx context.Context, event *domain.AuthEvent) error {
+	_, err := r.collection.InsertOne(ctx, authEventDocument{AuthEvent: *event, ExpiresAt: r.expiresAt(event)})
+	return err
+}
+
+func (r *MongoAuthEventRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.AuthEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	result, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close(ctx)
+
+	events := []*domain.AuthEvent{}
+	if err := result.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Anonymize also drops the reason where it may name the user: the previous
+// address on an email change and the admin's note on a disabled account.
+func (r *MongoAuthEventRepository) Anonymize(ctx context.Context, userID uuid.UUID, email string) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{
+		"user_id": userID,
+		"type":    bson.M{"$in": bson.A{domain.EventEmailChange, domain.EventAccountDisable}},
+	}, bson.M{"$unset": bson.M{"reason": ""}})
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"user_id": userID}
+	if email != "" {
+		filter = bson.M{"$or": bson.A{filter, bson.M{"email": email}}}
+	}
+	_, err = r.collection.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{
+		"email":      "",
+		"ip":         "",
+		"user_agent": "",
+	}})
+	return err
+}
+
+func (r *MongoAuthEventRepository) List(
+	ctx context.Context,
+	filter domain.AuthEventFilter,
+	cursor *uuid.UUID,
+	limit int,
+) ([]*domain.AuthEvent, *uuid.UUID, error) {
+	query := bson.M{}
+	if filter.UserID != nil {
+		query["user_id"] = *filter.UserID
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if filter.AdminOnly {
+		query["actor_id"] = bson.M{"$exists": true}
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lt"] = *filter.To
+		}
+		query["created_at"] = createdAt
+	}
+	if cursor != nil {
+		query["_id"] = bson.M{"$lt": *cursor}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1)) // +1 to detect a next page
+
+	result, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Close(ctx)
+
+	var events []*domain.AuthEvent
+	if err := result.All(ctx, &events); err != nil {
+		return nil, nil, err
+	}
+
+	if len(events) > limit {
+		events = events[:limit]
+		next := events[limit-1].ID
+		return events, &next, nil
+	}
+	return events, nil, nil
+}
@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+)
+
+func TestAuditRetentions(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.AuthConfig
+		want      time.Duration
+		wantAdmin time.Duration
+		wantErr   bool
+	}{
+		{name: "defaults", want: defaultAuditRetention},
+		{name: "configured", cfg: config.AuthConfig{AuditRetention: "720h"}, want: 720 * time.Hour},
+		{
+			name:      "admin retention",
+			cfg:       config.AuthConfig{AuditRetention: "720h", AdminAuditRetention: "87600h"},
+			want:      720 * time.Hour,
+			wantAdmin: 87600 * time.Hour,
+		},
+		{name: "zero", cfg: config.AuthConfig{AuditRetention: "0s"}, wantErr: true},
+		{name: "negative", cfg: config.AuthConfig{AuditRetention: "-1h"}, wantErr: true},
+		{name: "malformed", cfg: config.AuthConfig{AuditRetention: "90 days"}, wantErr: true},
+		{name: "admin zero", cfg: config.AuthConfig{AdminAuditRetention: "0s"}, wantErr: true},
+		{name: "admin malformed", cfg: config.AuthConfig{AdminAuditRetention: "forever"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotAdmin, err := auditRetentions(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("auditRetentions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || gotAdmin != tt.wantAdmin {
+				t.Errorf("auditRetentions() = %s, %s, want %s, %s", got, gotAdmin, tt.want, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	actor := uuid.New()
+	at := func(d time.Duration) *time.Time {
+		t := createdAt.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name           string
+		actorID        *uuid.UUID
+		adminRetention time.Duration
+		want           *time.Time
+	}{
+		{name: "sign-in event", want: at(time.Hour)},
+		{name: "sign-in event with admin retention", adminRetention: 24 * time.Hour, want: at(time.Hour)},
+		{name: "admin action kept", actorID: &actor},
+		{name: "admin action with retention", actorID: &actor, adminRetention: 24 * time.Hour, want: at(24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MongoAuthEventRepository{retention: time.Hour, adminRetention: tt.adminRetention}
+			got := repo.expiresAt(&domain.AuthEvent{ActorID: tt.actorID, CreatedAt: createdAt})
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("expiresAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,12 @@ package infrastructure
 import (
 	"context"
 	"errors"
+	"regexp"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bobshop/internal/modules/auth/domain"
 )
@@ -18,14 +21,77 @@ func NewMongoAuthRepository(db *mongo.Database) *MongoAuthRepository {
 	return &MongoAuthRepository{collection: db.Collection("users")}
 }
 
+// Create and Update report an email taken by another account, which the
+// unique index catches when two requests race past the application's check,
+// as ErrUserAlreadyExists.
 func (r *MongoAuthRepository) Create(ctx context.Context, user *domain.User) error {
 	_, err := r.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrUserAlreadyExists
+	}
 	return err
 }
 
 func (r *MongoAuthRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, bson.M{"email": domain.NormalizeEmail(email)}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *MongoAuthRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var user domain.User
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *MongoAuthRepository) Update(ctx context.Context, user *domain.User) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": user.ID}, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete is idempotent; deleting a missing user is not an error.
+func (r *MongoAuthRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *MongoAuthRepository) FindByIdentity(ctx context.Context, provider, subject string) (*domain.User, error) {
+	var user domain.User
+	filter := bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *MongoAuthRepository) FindByWebAuthnCredential(ctx context.Context, credentialID []byte) (*domain.User, error) {
+	var user domain.User
+	err := r.collection.FindOne(ctx, bson.M{"webauthn_credentials.id": credentialID}).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, domain.ErrUserNotFound
@@ -34,3 +100,55 @@ func (r *MongoAuthRepository) FindByEmail(ctx context.Context, email string) (*d
 	}
 	return &user, nil
 }
+
+func (r *MongoAuthRepository) List(
+	ctx context.Context,
+	filter domain.UserFilter,
+	cursor *uuid.UUID,
+	limit int,
+) ([]*domain.User, *uuid.UUID, error) {
+	query := bson.M{}
+	if filter.Query != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(filter.Query), "$options": "i"}
+		query["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"profile.display_name": pattern},
+		}
+	}
+	if filter.Role != nil {
+		query["role"] = *filter.Role
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			query["disabled_at"] = bson.M{"$ne": nil}
+		} else {
+			query["disabled_at"] = nil
+		}
+	}
+	// Ids are UUIDv7, so ordering by _id is ordering by creation time.
+	if cursor != nil {
+		query["_id"] = bson.M{"$lt": *cursor}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1)) // +1 to detect a next page
+
+	result, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Close(ctx)
+
+	var users []*domain.User
+	if err := result.All(ctx, &users); err != nil {
+		return nil, nil, err
+	}
+
+	if len(users) > limit {
+		users = users[:limit]
+		next := users[limit-1].ID
+		return users, &next, nil
+	}
+	return users, nil, nil
+}
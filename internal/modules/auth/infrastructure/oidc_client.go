@@ -0,0 +1,347 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+)
+
+const oidcCallbackPath = "/api/v1/auth/oidc/%s/callback"
+
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcProvider struct {
+	cfg config.OIDCProviderConfig
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]crypto.PublicKey
+}
+
+// HTTPOIDCClient is an OpenID Connect relying party for the authorization code
+// flow with PKCE. Provider metadata comes from discovery and signing keys from
+// the provider's JWKS, both fetched lazily and cached.
+type HTTPOIDCClient struct {
+	cfg        *config.OIDCConfig
+	httpClient *http.Client
+	providers  map[string]*oidcProvider
+}
+
+func NewHTTPOIDCClient(cfg *config.OIDCConfig) *HTTPOIDCClient {
+	providers := make(map[string]*oidcProvider, len(cfg.Providers))
+	for name, providerCfg := range cfg.Providers {
+		providers[name] = &oidcProvider{cfg: providerCfg}
+	}
+	return &HTTPOIDCClient{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		providers:  providers,
+	}
+}
+
+func (c *HTTPOIDCClient) AuthCodeURL(ctx context.Context, req *domain.OIDCAuthRequest) (string, error) {
+	provider, err := c.provider(req.Provider)
+	if err != nil {
+		return "", err
+	}
+	discovery, err := c.discover(ctx, provider)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := provider.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.cfg.ClientID},
+		"redirect_uri":          {c.redirectURI(req.Provider)},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {req.CodeChallenge()},
+		"code_challenge_method": {"S256"},
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	query := authURL.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+func (c *HTTPOIDCClient) Exchange(ctx context.Context, req *domain.OIDCAuthRequest, code string) (*domain.ExternalIdentity, error) {
+	provider, err := c.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	discovery, err := c.discover(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.redirectURI(req.Provider)},
+		"client_id":     {provider.cfg.ClientID},
+		"code_verifier": {req.CodeVerifier},
+	}
+	if provider.cfg.ClientSecret != "" {
+		form.Set("client_secret", provider.cfg.ClientSecret)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := c.do(httpReq, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("oidc token response has no id_token")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(tokenResponse.IDToken, claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return c.verificationKey(ctx, provider, discovery, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(provider.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id token: %w", err)
+	}
+	if nonce, _ := claims["nonce"].(string); nonce != req.Nonce {
+		return nil, errors.New("oidc id token nonce mismatch")
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.New("oidc id token has no subject")
+	}
+	email, _ := claims["email"].(string)
+	return &domain.ExternalIdentity{
+		Provider:      req.Provider,
+		Subject:       subject,
+		Email:         domain.NormalizeEmail(email),
+		EmailVerified: emailVerified(claims["email_verified"]),
+	}, nil
+}
+
+func (c *HTTPOIDCClient) provider(name string) (*oidcProvider, error) {
+	provider, ok := c.providers[name]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+func (c *HTTPOIDCClient) redirectURI(provider string) string {
+	return strings.TrimRight(c.cfg.RedirectBaseURL, "/") + fmt.Sprintf(oidcCallbackPath, provider)
+}
+
+func (c *HTTPOIDCClient) discover(ctx context.Context, provider *oidcProvider) (*oidcDiscovery, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if provider.discovery != nil {
+		return provider.discovery, nil
+	}
+
+	issuer := strings.TrimRight(provider.cfg.IssuerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var discovery oidcDiscovery
+	if err := c.do(req, &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", discovery.Issuer, provider.cfg.IssuerURL)
+	}
+	provider.discovery = &discovery
+	return provider.discovery, nil
+}
+
+// verificationKey refetches the provider's JWKS when it sees an unknown kid,
+// which is how providers roll their signing keys.
+func (c *HTTPOIDCClient) verificationKey(
+	ctx context.Context,
+	provider *oidcProvider,
+	discovery *oidcDiscovery,
+	kid string,
+) (crypto.PublicKey, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if key, ok := provider.keys[kid]; ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := c.do(req, &set); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, raw := range set.Keys {
+		id, key, err := parseJWK(raw)
+		if err != nil {
+			continue
+		}
+		keys[id] = key
+	}
+	provider.keys = keys
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("oidc jwks: unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (c *HTTPOIDCClient) do(req *http.Request, out any) error {
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL.Redacted(), res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func parseJWK(raw json.RawMessage) (string, crypto.PublicKey, error) {
+	var jwk struct {
+		KeyID   string `json:"kid"`
+		KeyType string `json:"kty"`
+		Use     string `json:"use"`
+		Curve   string `json:"crv"`
+		N       string `json:"n"`
+		E       string `json:"e"`
+		X       string `json:"x"`
+		Y       string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, errors.New("not a signing key")
+	}
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.KeyID, &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.KeyID, &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return "", nil, errors.New("invalid Ed25519 key")
+		}
+		return jwk.KeyID, ed25519.PublicKey(x), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// emailVerified accepts both the boolean form and the "true" string some
+// providers send.
+func emailVerified(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/config"
+)
+
+const (
+	testOIDCClientID = "bobshop"
+	testOIDCKeyID    = "test-key"
+)
+
+// mockIssuer is an OpenID provider serving discovery, a JWKS and a token
+// endpoint that checks the PKCE verifier against the challenge it was given.
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *ecdsa.PrivateKey
+
+	// authorize records what the browser would have sent to the provider.
+	challenge   string
+	nonce       string
+	redirectURI string
+
+	// idToken shapes the claims and signing of the issued ID token.
+	idToken func(claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey)
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": testOIDCKeyID,
+			"use": "sig",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+	mux.HandleFunc("POST /token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockIssuer) authorize(authURL string) {
+	m.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	query := u.Query()
+	if got := query.Get("code_challenge_method"); got != "S256" {
+		m.t.Fatalf("code_challenge_method = %q, want S256", got)
+	}
+	m.challenge = query.Get("code_challenge")
+	m.nonce = query.Get("nonce")
+	m.redirectURI = query.Get("redirect_uri")
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("code") != "auth-code" ||
+		r.PostForm.Get("redirect_uri") != m.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	claims, kid, key := m.idToken(jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            testOIDCClientID,
+		"sub":            "provider-user-1",
+		"email":          "Shopper@Example.com",
+		"email_verified": true,
+		"nonce":          m.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+	})
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	writeJSON(w, map[string]string{"id_token": signed, "token_type": "Bearer"})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestHTTPOIDCClientCallbackFlow(t *testing.T) {
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		idToken  func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey)
+		verifier string
+		wantErr  bool
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:     "wrong code verifier",
+			verifier: "not-the-verifier",
+			wantErr:  true,
+		},
+		{
+			name: "nonce mismatch",
+			idToken: func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				claims["nonce"] = "replayed"
+				return claims, testOIDCKeyID, m.key
+			},
+			wantErr: true,
+		},
+		{
+			name: "other audience",
+			idToken: func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				claims["aud"] = "someone-else"
+				return claims, testOIDCKeyID, m.key
+			},
+			wantErr: true,
+		},
+		{
+			name: "other issuer",
+			idToken: func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				claims["iss"] = "https://evil.example.com"
+				return claims, testOIDCKeyID, m.key
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			idToken: func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return claims, testOIDCKeyID, m.key
+			},
+			wantErr: true,
+		},
+		{
+			name: "signed by an unpublished key",
+			idToken: func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				return claims, testOIDCKeyID, otherKey
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown key id",
+			idToken: func(m *mockIssuer, claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				return claims, "rotated-away", m.key
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t)
+			issuer.idToken = func(claims jwt.MapClaims) (jwt.MapClaims, string, *ecdsa.PrivateKey) {
+				if tt.idToken != nil {
+					return tt.idToken(issuer, claims)
+				}
+				return claims, testOIDCKeyID, issuer.key
+			}
+			client := NewHTTPOIDCClient(&config.OIDCConfig{
+				RedirectBaseURL: "https://shop.example.com/",
+				Providers: map[string]config.OIDCProviderConfig{
+					"mock": {IssuerURL: issuer.server.URL, ClientID: testOIDCClientID},
+				},
+			})
+			ctx := context.Background()
+
+			req, err := domain.NewOIDCAuthRequest("mock")
+			if err != nil {
+				t.Fatal(err)
+			}
+			authURL, err := client.AuthCodeURL(ctx, req)
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			issuer.authorize(authURL)
+			if want := "https://shop.example.com/api/v1/auth/oidc/mock/callback"; issuer.redirectURI != want {
+				t.Errorf("redirect_uri = %q, want %q", issuer.redirectURI, want)
+			}
+			if tt.verifier != "" {
+				req.CodeVerifier = tt.verifier
+			}
+
+			identity, err := client.Exchange(ctx, req, "auth-code")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Exchange succeeded with identity %+v, want error", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			want := domain.ExternalIdentity{
+				Provider:      "mock",
+				Subject:       "provider-user-1",
+				Email:         "shopper@example.com",
+				EmailVerified: true,
+			}
+			if *identity != want {
+				t.Errorf("identity = %+v, want %+v", *identity, want)
+			}
+		})
+	}
+}
+
+func TestHTTPOIDCClientUnknownProvider(t *testing.T) {
+	client := NewHTTPOIDCClient(&config.OIDCConfig{})
+	req, err := domain.NewOIDCAuthRequest("missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AuthCodeURL(context.Background(), req); !errors.Is(err, domain.ErrUnknownProvider) {
+		t.Errorf("AuthCodeURL error = %v, want %v", err, domain.ErrUnknownProvider)
+	}
+}
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const (
+	actionTokenKey      = "action_token:%s:%s"
+	userActionTokensKey = "user:%s:action_tokens:%s"
+)
+
+func buildActionTokenKey(purpose domain.TokenPurpose, hash string) string {
+	return fmt.Sprintf(actionTokenKey, purpose, hash)
+}
+
+func buildUserActionTokensKey(userID uuid.UUID, purpose domain.TokenPurpose) string {
+	return fmt.Sprintf(userActionTokensKey, userID, purpose)
+}
+
+type RedisActionTokenStore struct {
+	client *redis.Client
+}
+
+func NewRedisActionTokenStore(client *redis.Client) *RedisActionTokenStore {
+	return &RedisActionTokenStore{client: client}
+}
+
+func (r *RedisActionTokenStore) Save(ctx context.Context, token *domain.ActionToken) error {
+	key := buildActionTokenKey(token.Purpose, token.Hash)
+	indexKey := buildUserActionTokensKey(token.UserID, token.Purpose)
+	ttl := time.Until(token.ExpiresAt)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, token.UserID.String(), ttl)
+		pipe.SAdd(ctx, indexKey, token.Hash)
+		extendTTLScript.Eval(ctx, pipe, []string{indexKey}, int64(ttl.Seconds()))
+		return nil
+	})
+	return err
+}
+
+// RevokeAll deletes the tokens listed in the user's index. Tokens already
+// used or expired are still listed, which is harmless.
+func (r *RedisActionTokenStore) RevokeAll(ctx context.Context, purpose domain.TokenPurpose, userID uuid.UUID) error {
+	indexKey := buildUserActionTokensKey(userID, purpose)
+	hashes, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(hashes)+1)
+	for _, hash := range hashes {
+		keys = append(keys, buildActionTokenKey(purpose, hash))
+	}
+	keys = append(keys, indexKey)
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *RedisActionTokenStore) Consume(ctx context.Context, purpose domain.TokenPurpose, hash string) (uuid.UUID, error) {
+	return parseActionTokenValue(r.client.GetDel(ctx, buildActionTokenKey(purpose, hash)).Result())
+}
+
+func (r *RedisActionTokenStore) Peek(ctx context.Context, purpose domain.TokenPurpose, hash string) (uuid.UUID, error) {
+	return parseActionTokenValue(r.client.Get(ctx, buildActionTokenKey(purpose, hash)).Result())
+}
+
+func parseActionTokenValue(value string, err error) (uuid.UUID, error) {
+	if errors.Is(err, redis.Nil) {
+		return uuid.Nil, domain.ErrInvalidActionToken
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	userID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidActionToken
+	}
+	return userID, nil
+}
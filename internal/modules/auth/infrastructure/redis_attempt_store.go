@@ -0,0 +1,57 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKey = "signin:failures:%s"
+	loginLockKey     = "signin:lock:%s"
+)
+
+type RedisLoginAttemptStore struct {
+	client *redis.Client
+}
+
+func NewRedisLoginAttemptStore(client *redis.Client) *RedisLoginAttemptStore {
+	return &RedisLoginAttemptStore{client: client}
+}
+
+func (r *RedisLoginAttemptStore) RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	failuresKey := fmt.Sprintf(loginFailuresKey, key)
+
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, failuresKey)
+		pipe.Expire(ctx, failuresKey, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *RedisLoginAttemptStore) Lock(ctx context.Context, key string, duration time.Duration) error {
+	return r.client.Set(ctx, fmt.Sprintf(loginLockKey, key), 1, duration).Err()
+}
+
+func (r *RedisLoginAttemptStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, fmt.Sprintf(loginLockKey, key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL reports -2 for a missing key and -1 for a key without expiry.
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (r *RedisLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return r.client.Del(ctx, fmt.Sprintf(loginFailuresKey, key), fmt.Sprintf(loginLockKey, key)).Err()
+}
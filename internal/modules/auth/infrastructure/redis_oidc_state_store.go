@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const oidcStateKey = "oidc_state:%s"
+
+type RedisOIDCStateStore struct {
+	client *redis.Client
+}
+
+func NewRedisOIDCStateStore(client *redis.Client) *RedisOIDCStateStore {
+	return &RedisOIDCStateStore{client: client}
+}
+
+func (r *RedisOIDCStateStore) Save(ctx context.Context, req *domain.OIDCAuthRequest, ttl time.Duration) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, fmt.Sprintf(oidcStateKey, req.State), data, ttl).Err()
+}
+
+func (r *RedisOIDCStateStore) Consume(ctx context.Context, state string) (*domain.OIDCAuthRequest, error) {
+	data, err := r.client.GetDel(ctx, fmt.Sprintf(oidcStateKey, state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrInvalidOIDCState
+	}
+	if err != nil {
+		return nil, err
+	}
+	var req domain.OIDCAuthRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,241 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/security"
+)
+
+const (
+	sessionKey      = "session:%s"
+	userSessionsKey = "user:%s:sessions"
+
+	sessionFieldUserID       = "user_id"
+	sessionFieldUserAgent    = "user_agent"
+	sessionFieldIP           = "ip"
+	sessionFieldMFA          = "mfa"
+	sessionFieldCreatedAt    = "created_at"
+	sessionFieldLastSeenAt   = "last_seen_at"
+	sessionFieldImpersonator = "impersonator_id"
+)
+
+// touchSessionScript updates last_seen_at only while the session still exists,
+// so a concurrent revoke cannot be undone by a late write.
+var touchSessionScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// extendTTLScript raises the TTL of a key to at least ARGV[1] seconds, so a
+// short-lived session does not cut the lifetime of the user's session index.
+var extendTTLScript = redis.NewScript(`
+local ttl = redis.call("TTL", KEYS[1])
+if ttl >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+return 1
+`)
+
+func buildSessionKey(id uuid.UUID) string {
+	return fmt.Sprintf(sessionKey, id)
+}
+
+func buildUserSessionsKey(userID uuid.UUID) string {
+	return fmt.Sprintf(userSessionsKey, userID)
+}
+
+type RedisSessionStore struct {
+	client *redis.Client
+}
+
+func NewRedisSessionStore(client *redis.Client) *RedisSessionStore {
+	return &RedisSessionStore{client: client}
+}
+
+func (r *RedisSessionStore) Create(ctx context.Context, session *domain.Session, ttl time.Duration) error {
+	key := buildSessionKey(session.ID)
+	indexKey := buildUserSessionsKey(session.UserID)
+
+	impersonator := ""
+	if session.ImpersonatorID != nil {
+		impersonator = session.ImpersonatorID.String()
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			sessionFieldUserID, session.UserID.String(),
+			sessionFieldUserAgent, session.UserAgent,
+			sessionFieldIP, session.IP,
+			sessionFieldMFA, session.MFA,
+			sessionFieldCreatedAt, session.CreatedAt.Unix(),
+			sessionFieldLastSeenAt, session.LastSeenAt.Unix(),
+			sessionFieldImpersonator, impersonator,
+		)
+		pipe.Expire(ctx, key, ttl)
+		pipe.SAdd(ctx, indexKey, session.ID.String())
+		extendTTLScript.Eval(ctx, pipe, []string{indexKey}, int64(ttl.Seconds()))
+		return nil
+	})
+	return err
+}
+
+func (r *RedisSessionStore) Get(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	fields, err := r.client.HGetAll(ctx, buildSessionKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+	return decodeSession(id, fields)
+}
+
+func (r *RedisSessionStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	indexKey := buildUserSessionsKey(userID)
+	members, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*domain.Session, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		session, err := r.Get(ctx, id)
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			// Expired sessions leave their id behind in the index.
+			r.client.SRem(ctx, indexKey, member)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (r *RedisSessionStore) Extend(ctx context.Context, id uuid.UUID, ttl time.Duration) error {
+	session, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	touched, err := r.touch(ctx, id, ttl)
+	if err != nil {
+		return err
+	}
+	if !touched {
+		return domain.ErrSessionNotFound
+	}
+	return r.client.Expire(ctx, buildUserSessionsKey(session.UserID), ttl).Err()
+}
+
+func (r *RedisSessionStore) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	session, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, buildSessionKey(id))
+		pipe.SRem(ctx, buildUserSessionsKey(userID), id.String())
+		return nil
+	})
+	return err
+}
+
+func (r *RedisSessionStore) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	indexKey := buildUserSessionsKey(userID)
+	members, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(members)+1)
+	for _, member := range members {
+		keys = append(keys, fmt.Sprintf(sessionKey, member))
+	}
+	keys = append(keys, indexKey)
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// ValidateSession implements security.SessionValidator for the auth middleware.
+func (r *RedisSessionStore) ValidateSession(ctx context.Context, sessionID, userID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return security.ErrSessionRevoked
+	}
+	owner, err := r.client.HGet(ctx, buildSessionKey(id), sessionFieldUserID).Result()
+	if errors.Is(err, redis.Nil) {
+		return security.ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if owner != userID {
+		return security.ErrSessionRevoked
+	}
+	_, err = r.touch(ctx, id, 0)
+	return err
+}
+
+func (r *RedisSessionStore) touch(ctx context.Context, id uuid.UUID, ttl time.Duration) (bool, error) {
+	keys := []string{buildSessionKey(id)}
+	touched, err := touchSessionScript.Run(ctx, r.client, keys,
+		sessionFieldLastSeenAt, time.Now().Unix(), int64(ttl.Seconds()),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return touched == 1, nil
+}
+
+func decodeSession(id uuid.UUID, fields map[string]string) (*domain.Session, error) {
+	userID, err := uuid.Parse(fields[sessionFieldUserID])
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	session := &domain.Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  fields[sessionFieldUserAgent],
+		IP:         fields[sessionFieldIP],
+		MFA:        fields[sessionFieldMFA] == "1",
+		CreatedAt:  parseUnix(fields[sessionFieldCreatedAt]),
+		LastSeenAt: parseUnix(fields[sessionFieldLastSeenAt]),
+	}
+	if impersonator, err := uuid.Parse(fields[sessionFieldImpersonator]); err == nil {
+		session.ImpersonatorID = &impersonator
+	}
+	return session, nil
+}
+
+func parseUnix(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
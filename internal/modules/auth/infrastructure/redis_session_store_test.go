@@ -0,0 +1,125 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/auth/domain"
+	"bobshop/internal/platform/security"
+)
+
+func TestValidateSession(t *testing.T) {
+	ctx := context.Background()
+	ann, bob := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name string
+		// revoke runs against a store holding two sessions of ann and one of bob.
+		revoke  func(store *RedisSessionStore, annFirst, annSecond, bobs *domain.Session) error
+		wantErr map[string]error
+	}{
+		{
+			name:    "nothing revoked",
+			revoke:  func(*RedisSessionStore, *domain.Session, *domain.Session, *domain.Session) error { return nil },
+			wantErr: map[string]error{},
+		},
+		{
+			name: "one session",
+			revoke: func(store *RedisSessionStore, annFirst, _, _ *domain.Session) error {
+				return store.Revoke(ctx, ann, annFirst.ID)
+			},
+			wantErr: map[string]error{"ann first": security.ErrSessionRevoked},
+		},
+		{
+			name: "someone else's session",
+			revoke: func(store *RedisSessionStore, _, _, bobs *domain.Session) error {
+				if err := store.Revoke(ctx, ann, bobs.ID); !errors.Is(err, domain.ErrSessionNotFound) {
+					return errors.New("revoked a session of another user")
+				}
+				return nil
+			},
+			wantErr: map[string]error{},
+		},
+		{
+			name: "all sessions",
+			revoke: func(store *RedisSessionStore, _, _, _ *domain.Session) error {
+				return store.RevokeAll(ctx, ann)
+			},
+			wantErr: map[string]error{
+				"ann first":  security.ErrSessionRevoked,
+				"ann second": security.ErrSessionRevoked,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestRedis(t)
+			store := NewRedisSessionStore(client)
+
+			sessions := map[string]*domain.Session{}
+			for name, userID := range map[string]uuid.UUID{"ann first": ann, "ann second": ann, "bob": bob} {
+				session, err := domain.NewSession(userID, domain.DeviceInfo{IP: "203.0.113.7"}, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := store.Create(ctx, session, time.Hour); err != nil {
+					t.Fatal(err)
+				}
+				sessions[name] = session
+			}
+			if err := tt.revoke(store, sessions["ann first"], sessions["ann second"], sessions["bob"]); err != nil {
+				t.Fatal(err)
+			}
+
+			for name, session := range sessions {
+				err := store.ValidateSession(ctx, session.ID.String(), session.UserID.String())
+				if !errors.Is(err, tt.wantErr[name]) {
+					t.Errorf("ValidateSession(%s) error = %v, want %v", name, err, tt.wantErr[name])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSessionClaims(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestRedis(t)
+	store := NewRedisSessionStore(client)
+
+	session, err := domain.NewSession(uuid.New(), domain.DeviceInfo{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(ctx, session, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		userID    string
+		wantErr   error
+	}{
+		{name: "matching", sessionID: session.ID.String(), userID: session.UserID.String()},
+		{name: "other user", sessionID: session.ID.String(), userID: uuid.NewString(), wantErr: security.ErrSessionRevoked},
+		{name: "unknown session", sessionID: uuid.NewString(), userID: session.UserID.String(), wantErr: security.ErrSessionRevoked},
+		{name: "malformed session", sessionID: "nope", userID: session.UserID.String(), wantErr: security.ErrSessionRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.ValidateSession(ctx, tt.sessionID, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	server.FastForward(2 * time.Hour)
+	if err := store.ValidateSession(ctx, session.ID.String(), session.UserID.String()); !errors.Is(err, security.ErrSessionRevoked) {
+		t.Errorf("ValidateSession() after expiry error = %v, want %v", err, security.ErrSessionRevoked)
+	}
+}
@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const (
+	refreshTokenKey = "refresh_token:%s"
+
+	refreshFieldSessionID = "session_id"
+	refreshFieldUserID    = "user_id"
+	refreshFieldUsedAt    = "used_at"
+)
+
+func buildRefreshTokenKey(hash string) string {
+	return fmt.Sprintf(refreshTokenKey, hash)
+}
+
+type RedisRefreshTokenStore struct {
+	client *redis.Client
+}
+
+func NewRedisRefreshTokenStore(client *redis.Client) *RedisRefreshTokenStore {
+	return &RedisRefreshTokenStore{client: client}
+}
+
+func (r *RedisRefreshTokenStore) Save(ctx context.Context, token *domain.RefreshToken) error {
+	tokenKey := buildRefreshTokenKey(token.Hash)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, tokenKey,
+			refreshFieldSessionID, token.SessionID.String(),
+			refreshFieldUserID, token.UserID.String(),
+		)
+		pipe.ExpireAt(ctx, tokenKey, token.ExpiresAt)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisRefreshTokenStore) Consume(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	tokenKey := buildRefreshTokenKey(hash)
+	fields, err := r.client.HGetAll(ctx, tokenKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	token, err := decodeRefreshToken(hash, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// HSETNX is atomic, so only one of two concurrent refreshes wins.
+	first, err := r.client.HSetNX(ctx, tokenKey, refreshFieldUsedAt, time.Now().Unix()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return token, domain.ErrRefreshTokenReused
+	}
+	return token, nil
+}
+
+func decodeRefreshToken(hash string, fields map[string]string) (*domain.RefreshToken, error) {
+	sessionID, err := uuid.Parse(fields[refreshFieldSessionID])
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	userID, err := uuid.Parse(fields[refreshFieldUserID])
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	return &domain.RefreshToken{
+		Hash:      hash,
+		SessionID: sessionID,
+		UserID:    userID,
+	}, nil
+}
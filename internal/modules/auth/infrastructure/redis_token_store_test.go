@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	sessionID, userID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name     string
+		consumes int
+		expire   bool
+		unknown  bool
+		wantErr  error
+	}{
+		{name: "first use", consumes: 1},
+		{name: "reused", consumes: 2, wantErr: domain.ErrRefreshTokenReused},
+		{name: "reused twice", consumes: 3, wantErr: domain.ErrRefreshTokenReused},
+		{name: "unknown", consumes: 1, unknown: true, wantErr: domain.ErrInvalidRefreshToken},
+		{name: "expired", consumes: 1, expire: true, wantErr: domain.ErrInvalidRefreshToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestRedis(t)
+			store := NewRedisRefreshTokenStore(client)
+
+			token, plain, err := domain.NewRefreshToken(sessionID, userID, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Save(ctx, token); err != nil {
+				t.Fatal(err)
+			}
+			if tt.unknown {
+				plain += "x"
+			}
+			if tt.expire {
+				server.FastForward(2 * time.Hour)
+			}
+
+			var got *domain.RefreshToken
+			for range tt.consumes {
+				got, err = store.Consume(ctx, domain.HashRefreshToken(plain))
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Consume() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == domain.ErrInvalidRefreshToken {
+				return
+			}
+			// A reused token still names its session so the caller can revoke it.
+			if got == nil || got.SessionID != sessionID || got.UserID != userID {
+				t.Errorf("Consume() = %+v, want session %s of user %s", got, sessionID, userID)
+			}
+		})
+	}
+}
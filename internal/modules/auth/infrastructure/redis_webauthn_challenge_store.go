@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/auth/domain"
+)
+
+const webAuthnChallengeKey = "webauthn_challenge:%s"
+
+type RedisWebAuthnChallengeStore struct {
+	client *redis.Client
+}
+
+func NewRedisWebAuthnChallengeStore(client *redis.Client) *RedisWebAuthnChallengeStore {
+	return &RedisWebAuthnChallengeStore{client: client}
+}
+
+func (r *RedisWebAuthnChallengeStore) Save(ctx context.Context, challenge *domain.WebAuthnChallenge, ttl time.Duration) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, fmt.Sprintf(webAuthnChallengeKey, challenge.Challenge), data, ttl).Err()
+}
+
+func (r *RedisWebAuthnChallengeStore) Consume(ctx context.Context, challenge string) (*domain.WebAuthnChallenge, error) {
+	data, err := r.client.GetDel(ctx, fmt.Sprintf(webAuthnChallengeKey, challenge)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrInvalidWebAuthnChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c domain.WebAuthnChallenge
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
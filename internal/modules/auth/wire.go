@@ -7,11 +7,34 @@ import (
 	"bobshop/internal/modules/auth/delivery/http"
 	"bobshop/internal/modules/auth/domain"
 	"bobshop/internal/modules/auth/infrastructure"
+	"bobshop/internal/platform/security"
 )
 
 var AuthSet = wire.NewSet(
 	wire.Bind(new(domain.AuthRepository), new(*infrastructure.MongoAuthRepository)),
+	wire.Bind(new(domain.AuthEventRepository), new(*infrastructure.MongoAuthEventRepository)),
+	wire.Bind(new(domain.RefreshTokenStore), new(*infrastructure.RedisRefreshTokenStore)),
+	wire.Bind(new(domain.SessionStore), new(*infrastructure.RedisSessionStore)),
+	wire.Bind(new(security.SessionValidator), new(*infrastructure.RedisSessionStore)),
+	wire.Bind(new(domain.ActionTokenStore), new(*infrastructure.RedisActionTokenStore)),
+	wire.Bind(new(domain.LoginAttemptStore), new(*infrastructure.RedisLoginAttemptStore)),
+	wire.Bind(new(domain.OIDCClient), new(*infrastructure.HTTPOIDCClient)),
+	wire.Bind(new(domain.OIDCStateStore), new(*infrastructure.RedisOIDCStateStore)),
+	wire.Bind(new(domain.WebAuthnChallengeStore), new(*infrastructure.RedisWebAuthnChallengeStore)),
+	wire.Bind(new(domain.BreachedPasswordChecker), new(*infrastructure.FileBreachedPasswordChecker)),
 	infrastructure.NewMongoAuthRepository,
+	infrastructure.NewMongoAuthEventRepository,
+	infrastructure.NewRedisRefreshTokenStore,
+	infrastructure.NewRedisSessionStore,
+	infrastructure.NewRedisActionTokenStore,
+	infrastructure.NewRedisLoginAttemptStore,
+	infrastructure.NewHTTPOIDCClient,
+	infrastructure.NewRedisOIDCStateStore,
+	infrastructure.NewRedisWebAuthnChallengeStore,
+	infrastructure.NewFileBreachedPasswordChecker,
 	application.NewAuthService,
+	application.NewUserDataSubject,
+	application.NewAuthEventDataSubject,
+	application.NewPasskeyDataSubject,
 	http.NewAuthHandler,
 )
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/category/domain"
+)
+
+// CategoryDirectory answers the lookups other modules make against the
+// category tree.
+type CategoryDirectory struct {
+	repo domain.CategoryRepository
+}
+
+func NewCategoryDirectory(repo domain.CategoryRepository) *CategoryDirectory {
+	return &CategoryDirectory{repo: repo}
+}
+
+// MissingCategories returns the IDs that name no category.
+func (d *CategoryDirectory) MissingCategories(ctx context.Context, ids []string) ([]string, error) {
+	known, err := d.findByRefs(ctx, ids, false)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(known))
+	for _, c := range known {
+		found[c.ID.String()] = true
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// ExpandCategories resolves IDs or slugs to the IDs of those categories and
+// all of their descendants.
+func (d *CategoryDirectory) ExpandCategories(ctx context.Context, refs []string) ([]string, error) {
+	roots, err := d.findByRefs(ctx, refs, true)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(roots))
+	for _, c := range roots {
+		paths = append(paths, c.Path)
+	}
+	subtrees, err := d.repo.FindSubtrees(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(subtrees))
+	for _, c := range subtrees {
+		ids = append(ids, c.ID.String())
+	}
+	return ids, nil
+}
+
+// CategoryLabels maps the given category IDs to their names and slugs,
+// leaving out IDs that name no category.
+func (d *CategoryDirectory) CategoryLabels(ctx context.Context, ids []string) (names, slugs map[string]string, err error) {
+	categories, err := d.findByRefs(ctx, ids, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	names = make(map[string]string, len(categories))
+	slugs = make(map[string]string, len(categories))
+	for _, c := range categories {
+		names[c.ID.String()] = c.Name
+		slugs[c.ID.String()] = c.Slug
+	}
+	return names, slugs, nil
+}
+
+func (d *CategoryDirectory) findByRefs(ctx context.Context, refs []string, withSlugs bool) ([]*domain.Category, error) {
+	var ids []uuid.UUID
+	var slugs []string
+	for _, ref := range refs {
+		if id, err := uuid.Parse(ref); err == nil {
+			ids = append(ids, id)
+		} else if withSlugs {
+			slugs = append(slugs, ref)
+		}
+	}
+	return d.repo.FindByRefs(ctx, ids, slugs)
+}
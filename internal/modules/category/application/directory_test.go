@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/category/domain"
+)
+
+// memCategories answers lookups from a slice, matching subtrees by path
+// prefix as the Mongo repository does.
+type memCategories struct {
+	domain.CategoryRepository
+	all []*domain.Category
+}
+
+func (m memCategories) FindByRefs(_ context.Context, ids []uuid.UUID, slugs []string) ([]*domain.Category, error) {
+	res := []*domain.Category{}
+	for _, c := range m.all {
+		if slices.Contains(ids, c.ID) || slices.Contains(slugs, c.Slug) {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+func (m memCategories) FindSubtrees(_ context.Context, paths []string) ([]*domain.Category, error) {
+	res := []*domain.Category{}
+	for _, c := range m.all {
+		if slices.ContainsFunc(paths, func(p string) bool { return strings.HasPrefix(c.Path, p) }) {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+func TestExpandCategories(t *testing.T) {
+	fashion := domain.NewCategory("Thời trang", "thoi-trang", nil)
+	shoes := domain.NewCategory("Giày", "giay", fashion)
+	running := domain.NewCategory("Giày chạy", "giay-chay", shoes)
+	hats := domain.NewCategory("Mũ", "mu", fashion)
+	home := domain.NewCategory("Nhà cửa", "nha-cua", nil)
+	d := NewCategoryDirectory(memCategories{all: []*domain.Category{fashion, shoes, running, hats, home}})
+
+	ids := func(cs ...*domain.Category) []string {
+		res := []string{}
+		for _, c := range cs {
+			res = append(res, c.ID.String())
+		}
+		return res
+	}
+
+	tests := []struct {
+		name string
+		refs []string
+		want []string
+	}{
+		{name: "leaf", refs: ids(running), want: ids(running)},
+		{name: "subtree by ID", refs: ids(shoes), want: ids(shoes, running)},
+		{name: "subtree by slug", refs: []string{"thoi-trang"}, want: ids(fashion, shoes, running, hats)},
+		{name: "overlapping refs", refs: []string{"giay", fashion.ID.String()}, want: ids(fashion, shoes, running, hats)},
+		{name: "several trees", refs: []string{"mu", "nha-cua"}, want: ids(hats, home)},
+		{name: "unknown", refs: []string{"khong-co", uuid.NewString()}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.ExpandCategories(context.Background(), tt.refs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandCategories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
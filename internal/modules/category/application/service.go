@@ -0,0 +1,156 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/category/delivery/http/dto"
+	"bobshop/internal/modules/category/domain"
+	"bobshop/pkg/textnorm"
+)
+
+type CategoryService struct {
+	repo     domain.CategoryRepository
+	listener domain.CategoryListener
+}
+
+func NewCategoryService(repo domain.CategoryRepository, listener domain.CategoryListener) *CategoryService {
+	return &CategoryService{repo: repo, listener: listener}
+}
+
+func (s *CategoryService) Create(ctx context.Context, req dto.CreateRequest) (*domain.Category, error) {
+	slug, err := validSlug(req.Slug, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	var parent *domain.Category
+	if req.ParentID != nil {
+		if parent, err = s.repo.FindByID(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	category := domain.NewCategory(req.Name, slug, parent)
+	category.Desc = req.Desc
+	category.Position = req.Position
+	category.SeoTitle = req.SeoTitle
+	category.SeoMeta = req.SeoMeta
+	category.ImageThumbnail = req.ImageThumbnail
+	category.ImageBanner = req.ImageBanner
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Update edits a category and, when it moves, carries its subtree along.
+func (s *CategoryService) Update(ctx context.Context, id uuid.UUID, req dto.UpdateRequest) (*domain.Category, error) {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := domain.CategoryChanges{
+		Name:           req.Name,
+		Desc:           req.Desc,
+		Position:       req.Position,
+		SeoTitle:       req.SeoTitle,
+		SeoMeta:        req.SeoMeta,
+		ImageThumbnail: req.ImageThumbnail,
+		ImageBanner:    req.ImageBanner,
+		UpdatedAt:      time.Now(),
+	}
+	if req.Slug != nil {
+		name := category.Name
+		if req.Name != nil {
+			name = *req.Name
+		}
+		slug, err := validSlug(*req.Slug, name)
+		if err != nil {
+			return nil, err
+		}
+		changes.Slug = &slug
+	}
+
+	oldName, oldPath, oldDepth := category.Name, category.Path, category.Depth
+	category.Apply(changes)
+	if req.ParentID != nil {
+		var parent *domain.Category
+		if *req.ParentID != "" {
+			if parent, err = s.Get(ctx, *req.ParentID); err != nil {
+				return nil, err
+			}
+		}
+		if err := category.MoveUnder(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	if category.Path != oldPath {
+		err = s.repo.Move(ctx, category, changes, oldPath, oldDepth)
+	} else {
+		err = s.repo.Update(ctx, id, changes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if category.Name != oldName {
+		s.listener.CategoryRenamed(ctx, category.ID)
+	}
+	return category, nil
+}
+
+// Delete removes a leaf category. Products keep the reference, which no
+// longer matches any category.
+func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID) error {
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return domain.ErrCategoryHasChildren
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.listener.CategoryRenamed(ctx, id)
+	return nil
+}
+
+// Get finds a category by ID or slug.
+func (s *CategoryService) Get(ctx context.Context, ref string) (*domain.Category, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return s.repo.FindByID(ctx, id)
+	}
+	return s.repo.FindBySlug(ctx, ref)
+}
+
+func (s *CategoryService) List(ctx context.Context) ([]*domain.Category, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *CategoryService) Tree(ctx context.Context) ([]*domain.Node, error) {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildTree(categories), nil
+}
+
+// validSlug defaults the slug to one made from the name and accepts only
+// slugs already in canonical form.
+func validSlug(slug, name string) (string, error) {
+	if slug == "" {
+		slug = textnorm.Slug(name)
+	}
+	if slug == "" || slug != textnorm.Slug(slug) {
+		return "", domain.ErrInvalidSlug
+	}
+	// Lookups take an ID or a slug, so a slug must not parse as an ID.
+	if _, err := uuid.Parse(slug); err == nil {
+		return "", domain.ErrInvalidSlug
+	}
+	return slug, nil
+}
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/category/delivery/http/dto"
+	"bobshop/internal/modules/category/domain"
+)
+
+// savingCategories records how Update saved a category.
+type savingCategories struct {
+	memCategories
+	updated *domain.CategoryChanges
+	moved   *domain.Category
+	oldPath string
+	moveErr error
+}
+
+func (m *savingCategories) FindByID(_ context.Context, id uuid.UUID) (*domain.Category, error) {
+	for _, c := range m.all {
+		if c.ID == id {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrCategoryNotFound
+}
+
+func (m *savingCategories) Update(_ context.Context, _ uuid.UUID, changes domain.CategoryChanges) error {
+	m.updated = &changes
+	return nil
+}
+
+func (m *savingCategories) Move(_ context.Context, category *domain.Category, changes domain.CategoryChanges, oldPath string, _ int) error {
+	m.moved, m.updated, m.oldPath = category, &changes, oldPath
+	return m.moveErr
+}
+
+type renames []uuid.UUID
+
+func (r *renames) CategoryRenamed(_ context.Context, id uuid.UUID) {
+	*r = append(*r, id)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		req         func(fashion, shoes, home *domain.Category) dto.UpdateRequest
+		moveErr     error
+		wantErr     error
+		wantMoved   bool
+		wantRenamed bool
+	}{
+		{
+			name:        "rename",
+			req:         func(_, _, _ *domain.Category) dto.UpdateRequest { return dto.UpdateRequest{Name: ptr("Giày dép")} },
+			wantRenamed: true,
+		},
+		{
+			name: "same name",
+			req:  func(_, _, _ *domain.Category) dto.UpdateRequest { return dto.UpdateRequest{Name: ptr("Giày")} },
+		},
+		{
+			name: "same parent",
+			req: func(fashion, _, _ *domain.Category) dto.UpdateRequest {
+				return dto.UpdateRequest{ParentID: ptr(fashion.ID.String())}
+			},
+		},
+		{
+			name: "move",
+			req: func(_, _, home *domain.Category) dto.UpdateRequest {
+				return dto.UpdateRequest{ParentID: ptr(home.ID.String())}
+			},
+			wantMoved: true,
+		},
+		{
+			name:      "move to the root",
+			req:       func(_, _, _ *domain.Category) dto.UpdateRequest { return dto.UpdateRequest{ParentID: ptr("")} },
+			wantMoved: true,
+		},
+		{
+			name: "moved meanwhile",
+			req: func(_, _, home *domain.Category) dto.UpdateRequest {
+				return dto.UpdateRequest{ParentID: ptr(home.ID.String())}
+			},
+			moveErr:   domain.ErrConcurrentMove,
+			wantErr:   domain.ErrConcurrentMove,
+			wantMoved: true,
+		},
+		{
+			name: "under itself",
+			req: func(_, shoes, _ *domain.Category) dto.UpdateRequest {
+				return dto.UpdateRequest{ParentID: ptr(shoes.ID.String())}
+			},
+			wantErr: domain.ErrInvalidParent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fashion := domain.NewCategory("Thời trang", "thoi-trang", nil)
+			shoes := domain.NewCategory("Giày", "giay", fashion)
+			home := domain.NewCategory("Nhà cửa", "nha-cua", nil)
+			repo := &savingCategories{
+				memCategories: memCategories{all: []*domain.Category{fashion, shoes, home}},
+				moveErr:       tt.moveErr,
+			}
+			listener := &renames{}
+			s := NewCategoryService(repo, listener)
+
+			_, err := s.Update(ctx, shoes.ID, tt.req(fashion, shoes, home))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if moved := repo.moved != nil; moved != tt.wantMoved {
+				t.Fatalf("moved = %v, want %v", moved, tt.wantMoved)
+			}
+			if tt.wantMoved && repo.oldPath != shoes.Path {
+				t.Errorf("Move() old path = %q, want %q", repo.oldPath, shoes.Path)
+			}
+			if tt.wantErr == nil && repo.updated == nil {
+				t.Error("changes not saved")
+			}
+			if renamed := len(*listener) == 1; renamed != tt.wantRenamed {
+				t.Errorf("renamed = %v, want %v", renamed, tt.wantRenamed)
+			}
+		})
+	}
+}
+
+func TestUpdateSavesOnlyEditedFields(t *testing.T) {
+	shoes := domain.NewCategory("Giày", "giay", nil)
+	shoes.Desc = "Giày các loại"
+	repo := &savingCategories{memCategories: memCategories{all: []*domain.Category{shoes}}}
+	s := NewCategoryService(repo, &renames{})
+
+	slug := "giay-dep"
+	if _, err := s.Update(context.Background(), shoes.ID, dto.UpdateRequest{Slug: &slug}); err != nil {
+		t.Fatal(err)
+	}
+	changes := repo.updated
+	if changes.Slug == nil || *changes.Slug != "giay-dep" {
+		t.Errorf("Slug = %v, want giay-dep", changes.Slug)
+	}
+	if changes.Name != nil || changes.Desc != nil || changes.Position != nil || changes.UpdatedAt.IsZero() {
+		t.Errorf("changes = %+v, want the slug and update time only", changes)
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateRequest struct {
+	Name           string     `json:"name" validate:"required,max=100"`
+	Slug           string     `json:"slug" validate:"omitempty,max=100"`
+	ParentID       *uuid.UUID `json:"parent_id" validate:"omitempty"`
+	Desc           string     `json:"desc" validate:"omitempty,max=2000"`
+	Position       int        `json:"position" validate:"omitempty"`
+	SeoTitle       string     `json:"seo_title" validate:"omitempty,max=200"`
+	SeoMeta        string     `json:"seo_meta" validate:"omitempty,max=500"`
+	ImageThumbnail string     `json:"image_thumbnail" validate:"omitempty,url"`
+	ImageBanner    string     `json:"image_banner" validate:"omitempty,url"`
+}
+
+type UpdateRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Slug *string `json:"slug" validate:"omitempty,min=1,max=100"`
+	// ParentID moves the category; an empty string moves it to the root.
+	ParentID       *string `json:"parent_id" validate:"omitempty,uuid|len=0"`
+	Desc           *string `json:"desc" validate:"omitempty,max=2000"`
+	Position       *int    `json:"position" validate:"omitempty"`
+	SeoTitle       *string `json:"seo_title" validate:"omitempty,max=200"`
+	SeoMeta        *string `json:"seo_meta" validate:"omitempty,max=500"`
+	ImageThumbnail *string `json:"image_thumbnail" validate:"omitempty,url|len=0"`
+	ImageBanner    *string `json:"image_banner" validate:"omitempty,url|len=0"`
+}
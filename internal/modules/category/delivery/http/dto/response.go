@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/category/domain"
+)
+
+type CategoryResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	ParentID       *uuid.UUID  `json:"parent_id"`
+	AncestorIDs    []uuid.UUID `json:"ancestor_ids"`
+	Depth          int         `json:"depth"`
+	Name           string      `json:"name"`
+	Slug           string      `json:"slug"`
+	Desc           string      `json:"desc"`
+	Position       int         `json:"position"`
+	SeoTitle       string      `json:"seo_title"`
+	SeoMeta        string      `json:"seo_meta"`
+	ImageThumbnail string      `json:"image_thumbnail"`
+	ImageBanner    string      `json:"image_banner"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func ToCategoryResponse(c *domain.Category) *CategoryResponse {
+	return &CategoryResponse{
+		ID:             c.ID,
+		ParentID:       c.ParentID,
+		AncestorIDs:    c.AncestorIDs(),
+		Depth:          c.Depth,
+		Name:           c.Name,
+		Slug:           c.Slug,
+		Desc:           c.Desc,
+		Position:       c.Position,
+		SeoTitle:       c.SeoTitle,
+		SeoMeta:        c.SeoMeta,
+		ImageThumbnail: c.ImageThumbnail,
+		ImageBanner:    c.ImageBanner,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
+	}
+}
+
+func ToCategoryResponses(categories []*domain.Category) []*CategoryResponse {
+	res := make([]*CategoryResponse, 0, len(categories))
+	for _, c := range categories {
+		res = append(res, ToCategoryResponse(c))
+	}
+	return res
+}
+
+type TreeNodeResponse struct {
+	ID             uuid.UUID           `json:"id"`
+	Name           string              `json:"name"`
+	Slug           string              `json:"slug"`
+	Position       int                 `json:"position"`
+	ImageThumbnail string              `json:"image_thumbnail"`
+	Children       []*TreeNodeResponse `json:"children"`
+}
+
+func ToTreeResponse(nodes []*domain.Node) []*TreeNodeResponse {
+	res := make([]*TreeNodeResponse, 0, len(nodes))
+	for _, n := range nodes {
+		res = append(res, &TreeNodeResponse{
+			ID:             n.ID,
+			Name:           n.Name,
+			Slug:           n.Slug,
+			Position:       n.Position,
+			ImageThumbnail: n.ImageThumbnail,
+			Children:       ToTreeResponse(n.Children),
+		})
+	}
+	return res
+}
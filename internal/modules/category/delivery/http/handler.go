@@ -0,0 +1,131 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"bobshop/internal/modules/category/application"
+	"bobshop/internal/modules/category/delivery/http/dto"
+	"bobshop/internal/modules/category/domain"
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/web"
+)
+
+type CategoryHandler struct {
+	service  *application.CategoryService
+	validate *validator.Validate
+}
+
+func NewCategoryHandler(service *application.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+func (h *CategoryHandler) Create(c *gin.Context) {
+	var req dto.CreateRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	category, err := h.service.Create(c.Request.Context(), req)
+	if err != nil {
+		categoryFailed(c, err)
+		return
+	}
+
+	response.Created(c, "Category created", dto.ToCategoryResponse(category))
+}
+
+func (h *CategoryHandler) Update(c *gin.Context) {
+	id, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	var req dto.UpdateRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	category, err := h.service.Update(c.Request.Context(), id, req)
+	if err != nil {
+		categoryFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Category updated", dto.ToCategoryResponse(category))
+}
+
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	id, err := web.GetIDParam(c)
+	if err != nil {
+		response.BadRequest(c, "invalid id", err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		categoryFailed(c, err)
+		return
+	}
+
+	response.NoContent(c, "Category deleted")
+}
+
+// Get serves category pages, which link by slug as well as by ID.
+func (h *CategoryHandler) Get(c *gin.Context) {
+	category, err := h.service.Get(c.Request.Context(), c.Param(web.IDParamKey))
+	if err != nil {
+		categoryFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Category found", dto.ToCategoryResponse(category))
+}
+
+func (h *CategoryHandler) List(c *gin.Context) {
+	categories, err := h.service.List(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Categories listed", dto.ToCategoryResponses(categories))
+}
+
+func (h *CategoryHandler) Tree(c *gin.Context) {
+	tree, err := h.service.Tree(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Category tree", dto.ToTreeResponse(tree))
+}
+
+func categoryFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		response.NotFound(c, err)
+	case errors.Is(err, domain.ErrSlugTaken):
+		response.Conflict(c, "slug already taken", err)
+	case errors.Is(err, domain.ErrCategoryHasChildren):
+		response.Conflict(c, "category has subcategories", err)
+	case errors.Is(err, domain.ErrConcurrentMove):
+		response.Conflict(c, "category was moved meanwhile, try again", err)
+	case errors.Is(err, domain.ErrInvalidSlug):
+		response.BadRequest(c, "invalid slug", err)
+	case errors.Is(err, domain.ErrInvalidParent):
+		response.BadRequest(c, "invalid parent", err)
+	default:
+		response.InternalError(c, err)
+	}
+}
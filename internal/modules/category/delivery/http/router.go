@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/security"
+)
+
+func RegisterRoutes(group *gin.RouterGroup, authMiddleware gin.HandlerFunc, h *CategoryHandler) {
+	categories := group.Group("/categories")
+	{
+		admin := categories.Group("", authMiddleware, middleware.RequirePermission(security.PermCategoryWrite))
+		admin.POST("", h.Create)
+		admin.PUT("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+
+		categories.GET("", h.List)
+		categories.GET("/tree", h.Tree)
+		categories.GET("/:id", h.Get)
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const pathSeparator = "/"
+
+// Category is a node in the catalogue tree. Path is the materialized path of
+// IDs from the root down to and including the category, e.g. "/a/b/", so a
+// subtree is every category whose path starts with its root's path.
+type Category struct {
+	ID             uuid.UUID  `bson:"_id"`
+	ParentID       *uuid.UUID `bson:"parent_id"`
+	Path           string     `bson:"path"`
+	Depth          int        `bson:"depth"`
+	Name           string     `bson:"name"`
+	Slug           string     `bson:"slug"`
+	Desc           string     `bson:"desc"`
+	Position       int        `bson:"position"`
+	SeoTitle       string     `bson:"seotitle"`
+	SeoMeta        string     `bson:"seometa"`
+	ImageThumbnail string     `bson:"image_thumbnail"`
+	ImageBanner    string     `bson:"image_banner"`
+	CreatedAt      time.Time  `bson:"created_at"`
+	UpdatedAt      time.Time  `bson:"updated_at"`
+}
+
+// CategoryChanges are the fields an edit sets; nil fields keep their value.
+// Saving only these leaves fields a concurrent edit or move wrote alone.
+type CategoryChanges struct {
+	Name           *string   `bson:"name,omitempty"`
+	Slug           *string   `bson:"slug,omitempty"`
+	Desc           *string   `bson:"desc,omitempty"`
+	Position       *int      `bson:"position,omitempty"`
+	SeoTitle       *string   `bson:"seotitle,omitempty"`
+	SeoMeta        *string   `bson:"seometa,omitempty"`
+	ImageThumbnail *string   `bson:"image_thumbnail,omitempty"`
+	ImageBanner    *string   `bson:"image_banner,omitempty"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+// Apply copies the changes onto the category.
+func (c *Category) Apply(changes CategoryChanges) {
+	set := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	set(&c.Name, changes.Name)
+	set(&c.Slug, changes.Slug)
+	set(&c.Desc, changes.Desc)
+	set(&c.SeoTitle, changes.SeoTitle)
+	set(&c.SeoMeta, changes.SeoMeta)
+	set(&c.ImageThumbnail, changes.ImageThumbnail)
+	set(&c.ImageBanner, changes.ImageBanner)
+	if changes.Position != nil {
+		c.Position = *changes.Position
+	}
+	c.UpdatedAt = changes.UpdatedAt
+}
+
+func NewCategory(name, slug string, parent *Category) *Category {
+	now := time.Now()
+	c := &Category{
+		ID:        uuid.New(),
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	c.placeUnder(parent)
+	return c
+}
+
+func (c *Category) placeUnder(parent *Category) {
+	if parent == nil {
+		c.ParentID = nil
+		c.Path = pathSeparator + c.ID.String() + pathSeparator
+		c.Depth = 0
+		return
+	}
+	parentID := parent.ID
+	c.ParentID = &parentID
+	c.Path = parent.Path + c.ID.String() + pathSeparator
+	c.Depth = parent.Depth + 1
+}
+
+// MoveUnder re-parents the category, nil meaning the root. A category cannot
+// move into its own subtree.
+func (c *Category) MoveUnder(parent *Category) error {
+	if parent != nil && c.IsAncestorOf(parent) {
+		return ErrInvalidParent
+	}
+	c.placeUnder(parent)
+	return nil
+}
+
+// ParentPath is the path of the category's parent, or the empty string for a
+// root category.
+func (c *Category) ParentPath() string {
+	if c.ParentID == nil {
+		return ""
+	}
+	return strings.TrimSuffix(c.Path, c.ID.String()+pathSeparator)
+}
+
+// IsAncestorOf reports whether other lies in c's subtree, c included.
+func (c *Category) IsAncestorOf(other *Category) bool {
+	return strings.HasPrefix(other.Path, c.Path)
+}
+
+// AncestorIDs lists the IDs on the path above the category, root first.
+func (c *Category) AncestorIDs() []uuid.UUID {
+	parts := strings.Split(strings.Trim(c.Path, pathSeparator), pathSeparator)
+	ids := make([]uuid.UUID, 0, len(parts))
+	for _, part := range parts[:len(parts)-1] {
+		if id, err := uuid.Parse(part); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMoveUnder(t *testing.T) {
+	root := NewCategory("Thời trang", "thoi-trang", nil)
+	child := NewCategory("Giày", "giay", root)
+	grandchild := NewCategory("Giày chạy", "giay-chay", child)
+	other := NewCategory("Nhà cửa", "nha-cua", nil)
+
+	tests := []struct {
+		name      string
+		category  *Category
+		parent    *Category
+		wantErr   error
+		wantPath  string
+		wantDepth int
+	}{
+		{name: "under itself", category: child, parent: child, wantErr: ErrInvalidParent},
+		{name: "under its child", category: root, parent: child, wantErr: ErrInvalidParent},
+		{name: "under its grandchild", category: root, parent: grandchild, wantErr: ErrInvalidParent},
+		{
+			name:      "under another tree",
+			category:  NewCategory("Giày", "giay", root),
+			parent:    other,
+			wantDepth: 1,
+		},
+		{name: "to the root", category: NewCategory("Giày", "giay", root)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := *tt.category
+			err := tt.category.MoveUnder(tt.parent)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoveUnder() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if *tt.category != before {
+					t.Errorf("rejected move changed the category to %+v", tt.category)
+				}
+				return
+			}
+
+			wantPath := "/" + tt.category.ID.String() + "/"
+			var wantParent *uuid.UUID
+			if tt.parent != nil {
+				wantPath = tt.parent.Path + tt.category.ID.String() + "/"
+				wantParent = &tt.parent.ID
+			}
+			if tt.category.Path != wantPath || tt.category.Depth != tt.wantDepth {
+				t.Errorf("path, depth = %q, %d, want %q, %d", tt.category.Path, tt.category.Depth, wantPath, tt.wantDepth)
+			}
+			if !reflect.DeepEqual(tt.category.ParentID, wantParent) {
+				t.Errorf("parent = %v, want %v", tt.category.ParentID, wantParent)
+			}
+		})
+	}
+}
+
+func TestAncestorIDs(t *testing.T) {
+	root := NewCategory("A", "a", nil)
+	child := NewCategory("B", "b", root)
+	grandchild := NewCategory("C", "c", child)
+
+	tests := []struct {
+		name     string
+		category *Category
+		want     []uuid.UUID
+	}{
+		{name: "root", category: root, want: []uuid.UUID{}},
+		{name: "child", category: child, want: []uuid.UUID{root.ID}},
+		{name: "grandchild", category: grandchild, want: []uuid.UUID{root.ID, child.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.category.AncestorIDs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AncestorIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParentPath(t *testing.T) {
+	root := NewCategory("A", "a", nil)
+	child := NewCategory("B", "b", root)
+	grandchild := NewCategory("C", "c", child)
+
+	tests := []struct {
+		name     string
+		category *Category
+		want     string
+	}{
+		{name: "root", category: root, want: ""},
+		{name: "child", category: child, want: root.Path},
+		{name: "grandchild", category: grandchild, want: child.Path},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.category.ParentPath(); got != tt.want {
+				t.Errorf("ParentPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryChanges(t *testing.T) {
+	name, empty, position := "Giày", "", 0
+	at := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		changes  CategoryChanges
+		wantKeys []string
+	}{
+		{name: "nothing", changes: CategoryChanges{UpdatedAt: at}, wantKeys: []string{"updated_at"}},
+		{name: "name", changes: CategoryChanges{Name: &name, UpdatedAt: at}, wantKeys: []string{"name", "updated_at"}},
+		// A field cleared or zeroed is still an edit.
+		{
+			name:     "cleared",
+			changes:  CategoryChanges{Desc: &empty, Position: &position, UpdatedAt: at},
+			wantKeys: []string{"desc", "position", "updated_at"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var set bson.M
+			if err := bson.Unmarshal(raw, &set); err != nil {
+				t.Fatal(err)
+			}
+			keys := slices.Sorted(maps.Keys(set))
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("$set keys = %v, want %v", keys, tt.wantKeys)
+			}
+
+			category := NewCategory("Mũ", "mu", nil)
+			category.Desc, category.Position = "old", 3
+			before := *category
+			category.Apply(tt.changes)
+			if tt.changes.Name != nil && category.Name != name || tt.changes.Name == nil && category.Name != before.Name {
+				t.Errorf("Name = %q", category.Name)
+			}
+			if tt.changes.Desc != nil && (category.Desc != "" || category.Position != 0) {
+				t.Errorf("Desc, Position = %q, %d, want them cleared", category.Desc, category.Position)
+			}
+			if category.Slug != before.Slug || !category.UpdatedAt.Equal(at) {
+				t.Errorf("Slug, UpdatedAt = %q, %v", category.Slug, category.UpdatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrSlugTaken           = errors.New("category slug already taken")
+	ErrInvalidSlug         = errors.New("invalid category slug")
+	ErrInvalidParent       = errors.New("category cannot be moved under itself")
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	ErrConcurrentMove      = errors.New("category or its new parent was moved meanwhile")
+)
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CategoryListener is told about changes other modules derive data from.
+type CategoryListener interface {
+	// CategoryRenamed is called once a category's name changed or it was
+	// deleted, so the name held elsewhere is stale.
+	CategoryRenamed(ctx context.Context, id uuid.UUID)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *Category) error
+	// Update saves the changes to the category with the ID.
+	Update(ctx context.Context, id uuid.UUID, changes CategoryChanges) error
+	// Move saves the changes to a category that moved from oldPath and
+	// rewrites the paths and depths of its descendants to match, all in one
+	// transaction. It fails with ErrConcurrentMove when the category or its
+	// new parent moved since they were read.
+	Move(ctx context.Context, category *Category, changes CategoryChanges, oldPath string, oldDepth int) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Category, error)
+	FindBySlug(ctx context.Context, slug string) (*Category, error)
+	// FindByRefs returns the categories matching any of the IDs or slugs.
+	FindByRefs(ctx context.Context, ids []uuid.UUID, slugs []string) ([]*Category, error)
+	// FindSubtrees returns every category under any of the paths, including
+	// the categories at those paths.
+	FindSubtrees(ctx context.Context, paths []string) ([]*Category, error)
+	HasChildren(ctx context.Context, id uuid.UUID) (bool, error)
+	List(ctx context.Context) ([]*Category, error)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+)
+
+type Node struct {
+	*Category
+	Children []*Node
+}
+
+// BuildTree nests categories under their parents, siblings ordered by position
+// then name. Categories whose parent is missing are treated as roots.
+func BuildTree(categories []*Category) []*Node {
+	nodes := make(map[string]*Node, len(categories))
+	for _, c := range categories {
+		nodes[c.ID.String()] = &Node{Category: c, Children: []*Node{}}
+	}
+
+	roots := []*Node{}
+	for _, c := range categories {
+		node := nodes[c.ID.String()]
+		if c.ParentID != nil {
+			if parent, ok := nodes[c.ParentID.String()]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	sortNodes(roots)
+	return roots
+}
+
+func sortNodes(nodes []*Node) {
+	slices.SortFunc(nodes, func(a, b *Node) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.Name, b.Name))
+	})
+	for _, node := range nodes {
+		sortNodes(node.Children)
+	}
+}
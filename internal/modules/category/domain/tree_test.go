@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// shape renders a tree as nested names, e.g. "A(B C) D".
+func shape(nodes []*Node) string {
+	s := ""
+	for i, n := range nodes {
+		if i > 0 {
+			s += " "
+		}
+		s += n.Name
+		if len(n.Children) > 0 {
+			s += "(" + shape(n.Children) + ")"
+		}
+	}
+	return s
+}
+
+func TestBuildTree(t *testing.T) {
+	a := NewCategory("A", "a", nil)
+	b := NewCategory("B", "b", a)
+	c := NewCategory("C", "c", a)
+	d := NewCategory("D", "d", b)
+	e := NewCategory("E", "e", nil)
+	first := NewCategory("Z", "z", nil)
+	first.Position = -1
+	orphan := NewCategory("O", "o", nil)
+	missing := uuid.New()
+	orphan.ParentID = &missing
+
+	tests := []struct {
+		name       string
+		categories []*Category
+		want       string
+	}{
+		{name: "empty", want: ""},
+		{name: "nested", categories: []*Category{a, b, c, d, e}, want: "A(B(D) C) E"},
+		{name: "input order does not matter", categories: []*Category{d, e, c, b, a}, want: "A(B(D) C) E"},
+		{name: "position before name", categories: []*Category{a, e, first}, want: "Z A E"},
+		{name: "missing parent becomes a root", categories: []*Category{a, orphan}, want: "A O"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shape(BuildTree(tt.categories)); got != tt.want {
+				t.Errorf("BuildTree() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
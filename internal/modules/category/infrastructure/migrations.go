@@ -0,0 +1,29 @@
+package infrastructure
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bobshop/internal/platform/database"
+)
+
+// Migrations are run by cmd/migrate.
+var Migrations = []database.Migration{
+	{
+		ID:          "20261018_categories_indexes",
+		Description: "index categories by slug, path and parent",
+		Up:          indexCategories,
+	},
+}
+
+func indexCategories(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("categories").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "path", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "position", Value: 1}}},
+	})
+	return err
+}
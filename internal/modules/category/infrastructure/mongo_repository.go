@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bobshop/internal/modules/category/domain"
+)
+
+type MongoCategoryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCategoryRepository(db *mongo.Database) *MongoCategoryRepository {
+	return &MongoCategoryRepository{collection: db.Collection("categories")}
+}
+
+func (r *MongoCategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	_, err := r.collection.InsertOne(ctx, category)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSlugTaken
+	}
+	return err
+}
+
+func (r *MongoCategoryRepository) Update(ctx context.Context, id uuid.UUID, changes domain.CategoryChanges) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": changes})
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSlugTaken
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// Move needs MongoDB to run as a replica set, which transactions require.
+//
+// The category and its new parent are only written if their paths are still
+// the ones the move was worked out from; otherwise a concurrent move could
+// have put the parent inside the category's subtree and closed a cycle.
+// Writing the parent too, rather than just reading it, makes two moves that
+// each depend on the other's category conflict, so one of them retries and
+// sees the other's result.
+func (r *MongoCategoryRepository) Move(
+	ctx context.Context,
+	category *domain.Category,
+	changes domain.CategoryChanges,
+	oldPath string,
+	oldDepth int,
+) error {
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx mongo.SessionContext) (any, error) {
+		if err := r.place(ctx, category, changes, oldPath); err != nil {
+			return nil, err
+		}
+		if category.ParentID != nil {
+			if err := r.touchParent(ctx, *category.ParentID, category.ParentPath(), changes.UpdatedAt); err != nil {
+				return nil, err
+			}
+		}
+		return nil, r.moveSubtree(ctx, oldPath, category.Path, category.Depth-oldDepth)
+	})
+	return err
+}
+
+// placement is what a move sets on the category itself.
+type placement struct {
+	domain.CategoryChanges `bson:",inline"`
+	ParentID               *uuid.UUID `bson:"parent_id"`
+	Path                   string     `bson:"path"`
+	Depth                  int        `bson:"depth"`
+}
+
+// place saves the changes and the category's new position, provided it is
+// still at oldPath.
+func (r *MongoCategoryRepository) place(
+	ctx context.Context,
+	category *domain.Category,
+	changes domain.CategoryChanges,
+	oldPath string,
+) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": category.ID, "path": oldPath},
+		bson.M{"$set": placement{
+			CategoryChanges: changes,
+			ParentID:        category.ParentID,
+			Path:            category.Path,
+			Depth:           category.Depth,
+		}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSlugTaken
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.movedOrMissing(ctx, category.ID)
+	}
+	return nil
+}
+
+// touchParent marks the parent updated, its subtree having changed, provided
+// it is still at path.
+func (r *MongoCategoryRepository) touchParent(ctx context.Context, id uuid.UUID, path string, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "path": path},
+		bson.M{"$set": bson.M{"updated_at": at}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.movedOrMissing(ctx, id)
+	}
+	return nil
+}
+
+// movedOrMissing tells why a category was not found at the path expected.
+func (r *MongoCategoryRepository) movedOrMissing(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return domain.ErrConcurrentMove
+}
+
+func (r *MongoCategoryRepository) moveSubtree(ctx context.Context, oldPath, newPath string, depthDelta int) error {
+	filter := bson.M{"path": bson.M{"$regex": subtreePattern(oldPath)}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"path": bson.M{"$concat": bson.A{
+			newPath,
+			bson.M{"$substrBytes": bson.A{"$path", len(oldPath), bson.M{"$strLenBytes": "$path"}}},
+		}},
+		"depth": bson.M{"$add": bson.A{"$depth", depthDelta}},
+	}}}}
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (r *MongoCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *MongoCategoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoCategoryRepository) FindBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	return r.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (r *MongoCategoryRepository) findOne(ctx context.Context, filter bson.M) (*domain.Category, error) {
+	var category domain.Category
+	err := r.collection.FindOne(ctx, filter).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *MongoCategoryRepository) FindByRefs(ctx context.Context, ids []uuid.UUID, slugs []string) ([]*domain.Category, error) {
+	if len(ids) == 0 && len(slugs) == 0 {
+		return []*domain.Category{}, nil
+	}
+	// $in rejects null, which is how a nil slice encodes.
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+	if slugs == nil {
+		slugs = []string{}
+	}
+	return r.find(ctx, bson.M{"$or": bson.A{
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{"slug": bson.M{"$in": slugs}},
+	}})
+}
+
+func (r *MongoCategoryRepository) FindSubtrees(ctx context.Context, paths []string) ([]*domain.Category, error) {
+	if len(paths) == 0 {
+		return []*domain.Category{}, nil
+	}
+	subtrees := make(bson.A, 0, len(paths))
+	for _, path := range paths {
+		subtrees = append(subtrees, bson.M{"path": bson.M{"$regex": subtreePattern(path)}})
+	}
+	return r.find(ctx, bson.M{"$or": subtrees})
+}
+
+func (r *MongoCategoryRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"parent_id": id}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (r *MongoCategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *MongoCategoryRepository) find(ctx context.Context, filter bson.M) ([]*domain.Category, error) {
+	opts := options.Find().SetSort(bson.D{
+		{Key: "depth", Value: 1}, {Key: "position", Value: 1}, {Key: "name", Value: 1},
+	})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []*domain.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// subtreePattern is an anchored prefix match, which MongoDB answers from the
+// path index.
+func subtreePattern(path string) string {
+	return "^" + regexp.QuoteMeta(path)
+}
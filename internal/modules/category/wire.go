@@ -0,0 +1,18 @@
+package category
+
+import (
+	"github.com/google/wire"
+
+	"bobshop/internal/modules/category/application"
+	"bobshop/internal/modules/category/delivery/http"
+	"bobshop/internal/modules/category/domain"
+	"bobshop/internal/modules/category/infrastructure"
+)
+
+var CategorySet = wire.NewSet(
+	wire.Bind(new(domain.CategoryRepository), new(*infrastructure.MongoCategoryRepository)),
+	infrastructure.NewMongoCategoryRepository,
+	application.NewCategoryService,
+	application.NewCategoryDirectory,
+	http.NewCategoryHandler,
+)
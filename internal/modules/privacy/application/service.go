@@ -0,0 +1,133 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/privacy/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/privacy"
+)
+
+const (
+	defaultDeletionGracePeriod   = 30 * 24 * time.Hour
+	defaultDeletionCheckInterval = time.Hour
+	deletionBatchSize            = 50
+)
+
+type PrivacyService struct {
+	repo     domain.DeletionRequestRepository
+	subjects []privacy.DataSubject
+	cfg      *config.PrivacyConfig
+}
+
+// NewPrivacyService takes the data subjects in erasure order; the one that
+// deletes the account itself should come last so a failed run is retried.
+func NewPrivacyService(
+	repo domain.DeletionRequestRepository,
+	subjects []privacy.DataSubject,
+	cfg *config.PrivacyConfig,
+) *PrivacyService {
+	return &PrivacyService{
+		repo:     repo,
+		subjects: subjects,
+		cfg:      cfg,
+	}
+}
+
+// Export collects everything each module holds about the user, keyed by module.
+func (s *PrivacyService) Export(ctx context.Context, userID uuid.UUID) (map[string]any, error) {
+	archive := map[string]any{
+		"user_id":     userID,
+		"exported_at": time.Now(),
+	}
+	for _, subject := range s.subjects {
+		data, err := subject.ExportUserData(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", subject.Name(), err)
+		}
+		archive[subject.Name()] = data
+	}
+	return archive, nil
+}
+
+func (s *PrivacyService) RequestDeletion(ctx context.Context, userID uuid.UUID) (*domain.DeletionRequest, error) {
+	grace, err := parseDuration(s.cfg.DeletionGracePeriod, defaultDeletionGracePeriod)
+	if err != nil {
+		return nil, err
+	}
+	req := domain.NewDeletionRequest(userID, grace)
+	if err := s.repo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *PrivacyService) GetDeletion(ctx context.Context, userID uuid.UUID) (*domain.DeletionRequest, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+func (s *PrivacyService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.Delete(ctx, userID)
+}
+
+// RunDeletionWorker erases users whose grace period is over, checking on the
+// configured interval until ctx is cancelled.
+func (s *PrivacyService) RunDeletionWorker(ctx context.Context) {
+	interval, err := parseDuration(s.cfg.DeletionCheckInterval, defaultDeletionCheckInterval)
+	if err != nil {
+		log.Printf("privacy: invalid deletion check interval: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.processDueDeletions(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("privacy: processing deletions: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PrivacyService) processDueDeletions(ctx context.Context) error {
+	reqs, err := s.repo.ListDue(ctx, time.Now(), deletionBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		if err := s.erase(ctx, req.UserID); err != nil {
+			log.Printf("privacy: erasing user %s: %v", req.UserID, err)
+			continue
+		}
+		if err := s.repo.Delete(ctx, req.UserID); err != nil && !errors.Is(err, domain.ErrDeletionNotRequested) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PrivacyService) erase(ctx context.Context, userID uuid.UUID) error {
+	for _, subject := range s.subjects {
+		if err := subject.EraseUserData(ctx, userID); err != nil {
+			return fmt.Errorf("erase %s: %w", subject.Name(), err)
+		}
+	}
+	return nil
+}
+
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
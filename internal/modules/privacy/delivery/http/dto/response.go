@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"bobshop/internal/modules/privacy/domain"
+)
+
+type DeletionResponse struct {
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+func ToDeletionResponse(req *domain.DeletionRequest) *DeletionResponse {
+	return &DeletionResponse{
+		RequestedAt:  req.RequestedAt,
+		ScheduledFor: req.ScheduledFor,
+	}
+}
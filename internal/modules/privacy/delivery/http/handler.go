@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/modules/privacy/application"
+	"bobshop/internal/modules/privacy/delivery/http/dto"
+	"bobshop/internal/modules/privacy/domain"
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/web"
+)
+
+type PrivacyHandler struct {
+	service *application.PrivacyService
+}
+
+func NewPrivacyHandler(service *application.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: service}
+}
+
+// Export is served as a downloadable JSON file rather than wrapped in the
+// usual response envelope.
+func (h *PrivacyHandler) Export(c *gin.Context) {
+	archive, err := h.service.Export(c.Request.Context(), web.GetUserID(c))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("bobshop-export-%s.json", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.IndentedJSON(http.StatusOK, archive)
+}
+
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	req, err := h.service.RequestDeletion(c.Request.Context(), web.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrDeletionAlreadyRequested) {
+			response.Conflict(c, "account deletion already requested", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Account deletion scheduled", dto.ToDeletionResponse(req))
+}
+
+func (h *PrivacyHandler) GetDeletion(c *gin.Context) {
+	req, err := h.service.GetDeletion(c.Request.Context(), web.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrDeletionNotRequested) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account deletion scheduled", dto.ToDeletionResponse(req))
+}
+
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	if err := h.service.CancelDeletion(c.Request.Context(), web.GetUserID(c)); err != nil {
+		if errors.Is(err, domain.ErrDeletionNotRequested) {
+			response.NotFound(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.SimpleSuccess(c, "Account deletion cancelled")
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/middleware"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, authMiddleware gin.HandlerFunc, handler *PrivacyHandler) {
+	me := rg.Group("/me", authMiddleware, middleware.RequireUser())
+	{
+		me.GET("/export", middleware.BlockImpersonation(), handler.Export)
+		me.DELETE("", middleware.BlockImpersonation(), handler.RequestDeletion)
+		me.GET("/deletion", handler.GetDeletion)
+		me.DELETE("/deletion", middleware.BlockImpersonation(), handler.CancelDeletion)
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDeletionNotRequested     = errors.New("account deletion not requested")
+	ErrDeletionAlreadyRequested = errors.New("account deletion already requested")
+)
+
+// DeletionRequest schedules the erasure of a user's data. Until ScheduledFor
+// passes the user can cancel it.
+type DeletionRequest struct {
+	UserID       uuid.UUID `bson:"_id"`
+	RequestedAt  time.Time `bson:"requested_at"`
+	ScheduledFor time.Time `bson:"scheduled_for"`
+}
+
+func NewDeletionRequest(userID uuid.UUID, gracePeriod time.Duration) *DeletionRequest {
+	now := time.Now()
+	return &DeletionRequest{
+		UserID:       userID,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(gracePeriod),
+	}
+}
+
+type DeletionRequestRepository interface {
+	// Create fails with ErrDeletionAlreadyRequested if one is pending.
+	Create(ctx context.Context, req *DeletionRequest) error
+	FindByUser(ctx context.Context, userID uuid.UUID) (*DeletionRequest, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*DeletionRequest, error)
+}
@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bobshop/internal/modules/privacy/domain"
+)
+
+type MongoDeletionRequestRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDeletionRequestRepository(db *mongo.Database) *MongoDeletionRequestRepository {
+	return &MongoDeletionRequestRepository{collection: db.Collection("deletion_requests")}
+}
+
+func (r *MongoDeletionRequestRepository) Create(ctx context.Context, req *domain.DeletionRequest) error {
+	_, err := r.collection.InsertOne(ctx, req)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDeletionAlreadyRequested
+	}
+	return err
+}
+
+func (r *MongoDeletionRequestRepository) FindByUser(ctx context.Context, userID uuid.UUID) (*domain.DeletionRequest, error) {
+	var req domain.DeletionRequest
+	err := r.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&req)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDeletionNotRequested
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *MongoDeletionRequestRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrDeletionNotRequested
+	}
+	return nil
+}
+
+func (r *MongoDeletionRequestRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.DeletionRequest, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "scheduled_for", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"scheduled_for": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reqs []*domain.DeletionRequest
+	if err := cursor.All(ctx, &reqs); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
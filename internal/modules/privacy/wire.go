@@ -0,0 +1,17 @@
+package privacy
+
+import (
+	"github.com/google/wire"
+
+	"bobshop/internal/modules/privacy/application"
+	"bobshop/internal/modules/privacy/delivery/http"
+	"bobshop/internal/modules/privacy/domain"
+	"bobshop/internal/modules/privacy/infrastructure"
+)
+
+var PrivacySet = wire.NewSet(
+	wire.Bind(new(domain.DeletionRequestRepository), new(*infrastructure.MongoDeletionRequestRepository)),
+	infrastructure.NewMongoDeletionRequestRepository,
+	application.NewPrivacyService,
+	http.NewPrivacyHandler,
+)
@@ -0,0 +1,48 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/product/domain"
+)
+
+// ProductDataSubject covers the reviews a user wrote and the products they
+// recently viewed.
+type ProductDataSubject struct {
+	repo  domain.ProductRepository
+	cache domain.Cache
+}
+
+func NewProductDataSubject(repo domain.ProductRepository, cache domain.Cache) *ProductDataSubject {
+	return &ProductDataSubject{repo: repo, cache: cache}
+}
+
+type productUserData struct {
+	Reviews        []*domain.Review `json:"reviews"`
+	RecentlyViewed []string         `json:"recently_viewed"`
+}
+
+func (p *ProductDataSubject) Name() string {
+	return "products"
+}
+
+func (p *ProductDataSubject) ExportUserData(ctx context.Context, userID uuid.UUID) (any, error) {
+	reviews, err := p.repo.ListReviewsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	viewed, err := p.cache.GetRecentlyViewed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &productUserData{Reviews: reviews, RecentlyViewed: viewed}, nil
+}
+
+func (p *ProductDataSubject) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	if err := p.repo.AnonymizeReviews(ctx, userID); err != nil {
+		return err
+	}
+	return p.cache.ClearRecentlyViewed(ctx, userID)
+}
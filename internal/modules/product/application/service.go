@@ -2,6 +2,13 @@ package application
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,10 +16,22 @@ import (
 
 	"bobshop/internal/modules/product/delivery/http/dto"
 	"bobshop/internal/modules/product/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/pagination"
+	"bobshop/pkg/textnorm"
+)
+
+const (
+	defaultListLimit = 20
+	rebuildBatchSize = 100
+
+	categoryReindexTimeout = 5 * time.Minute
 )
 
 func fromCreateRequest(req dto.CreateRequest) *domain.Product {
-	return domain.NewProductBuilder(req.Name, req.Price).Build()
+	return domain.NewProductBuilder(req.Name, req.Price).
+		WithCategories(canonicalCategories(req.Categories)).
+		Build()
 }
 
 func fromUpdateRequest(req dto.UpdateRequest) bson.M {
@@ -25,40 +44,205 @@ func fromUpdateRequest(req dto.UpdateRequest) bson.M {
 	if req.Price != nil {
 		updateFields["price"] = *req.Price
 	}
+	if req.Categories != nil {
+		updateFields["categories"] = canonicalCategories(req.Categories)
+	}
 	return updateFields
 }
 
+// canonicalCategories writes category IDs the way the category module does, so
+// stored references compare equal to its IDs.
+func canonicalCategories(ids []string) []string {
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if parsed, err := uuid.Parse(id); err == nil {
+			id = parsed.String()
+		}
+		if !slices.Contains(res, id) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
 func fromAddReviewRequest(req dto.AddReviewRequest, productID, userID uuid.UUID) *domain.Review {
 	return domain.NewReview(productID, userID, req.Rating, req.Comment)
 }
 
 type ProductService struct {
-	repo  domain.ProductRepository
-	cache domain.Cache
+	repo       domain.ProductRepository
+	searcher   domain.Searcher
+	suggest    domain.SuggestIndex
+	categories domain.CategoryCatalog
+	cache      domain.Cache
+	cursors    *pagination.CursorCodec
+	searchCfg  *config.SearchConfig
 }
 
-func NewProductService(repo domain.ProductRepository, cache domain.Cache) *ProductService {
+func NewProductService(
+	repo domain.ProductRepository,
+	searcher domain.Searcher,
+	suggest domain.SuggestIndex,
+	categories domain.CategoryCatalog,
+	cache domain.Cache,
+	cursors *pagination.CursorCodec,
+	searchCfg *config.SearchConfig,
+) *ProductService {
 	return &ProductService{
-		repo:  repo,
-		cache: cache,
+		repo:       repo,
+		searcher:   searcher,
+		suggest:    suggest,
+		categories: categories,
+		cache:      cache,
+		cursors:    cursors,
+		searchCfg:  searchCfg,
 	}
 }
 
 func (s *ProductService) Create(ctx context.Context, req dto.CreateRequest) (*domain.Product, error) {
 	product := fromCreateRequest(req)
+	if err := s.checkCategories(ctx, product.Categories); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Create(ctx, product); err != nil {
 		return nil, err
 	}
+	s.reindexSuggestions(ctx, product.ID, product)
 	return product, nil
 }
 
 func (s *ProductService) Update(ctx context.Context, productID uuid.UUID, req dto.UpdateRequest) error {
 	updateFields := fromUpdateRequest(req)
-	return s.repo.Update(ctx, productID, updateFields)
+	if categories, ok := updateFields["categories"].([]string); ok {
+		if err := s.checkCategories(ctx, categories); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Update(ctx, productID, updateFields); err != nil {
+		return err
+	}
+	updated, err := s.indexedProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	s.reindexSuggestions(ctx, productID, updated)
+	return nil
 }
 
 func (s *ProductService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.reindexSuggestions(ctx, id, nil)
+	return nil
+}
+
+// indexedProduct returns the product as the suggestion index should know it,
+// or nil when it is inactive and so not indexed.
+func (s *ProductService) indexedProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return nil, nil
+	}
+	return product, err
+}
+
+func (s *ProductService) checkCategories(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	missing, err := s.categories.MissingCategories(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownCategory, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// reindexSuggestions replaces a product's entries in the suggestion index,
+// taking them out when product is nil. The index is derived data and is
+// rebuilt periodically, so failures are only logged.
+func (s *ProductService) reindexSuggestions(ctx context.Context, id uuid.UUID, product *domain.Product) {
+	if product == nil {
+		if err := s.suggest.Remove(ctx, id); err != nil {
+			log.Printf("product: removing suggestions for %s: %v", id, err)
+		}
+		return
+	}
+	// Without the category names the entry would lose its categories, so the
+	// product keeps its current entry until the next rebuild.
+	names, _, err := s.categories.CategoryLabels(ctx, product.Categories)
+	if err != nil {
+		log.Printf("product: naming categories of %s: %v", id, err)
+		return
+	}
+	if err := s.suggest.Index(ctx, id, product.Suggestions(names), product.Sales); err != nil {
+		log.Printf("product: indexing suggestions for %s: %v", id, err)
+	}
+}
+
+// RunSuggestIndexer indexes every live product at startup and again on the
+// configured interval, so products from before the index existed are found
+// and suggestions stay ranked by current sales.
+func (s *ProductService) RunSuggestIndexer(ctx context.Context) {
+	ticker := time.NewTicker(s.searchCfg.SuggestRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.RebuildSuggestions(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("product: rebuilding suggestions: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RebuildSuggestions indexes every live product again.
+func (s *ProductService) RebuildSuggestions(ctx context.Context) error {
+	return s.reindexProducts(ctx, nil)
+}
+
+// CategoryRenamed indexes the products of a renamed or deleted category again,
+// in the background. The periodic rebuild catches anything it misses.
+func (s *ProductService) CategoryRenamed(ctx context.Context, id uuid.UUID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), categoryReindexTimeout)
+		defer cancel()
+		filter := &domain.ListFilter{Categories: []string{id.String()}}
+		if err := s.reindexProducts(ctx, filter); err != nil {
+			log.Printf("product: reindexing category %s: %v", id, err)
+		}
+	}()
+}
+
+// reindexProducts indexes the live products passing the filter, page by page.
+func (s *ProductService) reindexProducts(ctx context.Context, filter *domain.ListFilter) error {
+	pagination := &domain.CursorPagination{Limit: rebuildBatchSize}
+	for {
+		products, page, err := s.repo.List(ctx, filter, pagination, &domain.Sort{})
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			s.reindexSuggestions(ctx, product.ID, product)
+		}
+		if page.Next == nil {
+			return nil
+		}
+		pagination.Cursor = page.Next
+	}
+}
+
+func (s *ProductService) Suggest(ctx context.Context, req dto.SuggestRequest) ([]domain.Suggestion, error) {
+	limit := domain.DefaultSuggestLimit
+	if req.Limit != nil {
+		limit = *req.Limit
+	}
+	return s.suggest.Suggest(ctx, req.Query, limit)
 }
 
 func (s *ProductService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
@@ -67,7 +251,6 @@ func (s *ProductService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pro
 
 func fromListFilterRequest(req dto.ListFilterRequest) *domain.ListFilter {
 	return &domain.ListFilter{
-		Name:       req.Name,
 		Categories: req.Categories,
 		Brands:     req.Brands,
 		Vendor:     req.Vendor,
@@ -77,30 +260,181 @@ func fromListFilterRequest(req dto.ListFilterRequest) *domain.ListFilter {
 	}
 }
 
-func fromCursorPaginationRequest(req dto.CursorPaginationRequest) *domain.CursorPagination {
-	return &domain.CursorPagination{
-		Cursor: req.Cursor,
-		Limit:  req.Limit,
+// searchText returns the free text to search for, empty when listing.
+func searchText(req dto.ListFilterRequest) string {
+	if req.Query != nil {
+		return strings.TrimSpace(*req.Query)
 	}
+	if req.Name != nil {
+		return strings.TrimSpace(*req.Name)
+	}
+	return ""
 }
 
-func fromSortRequest(req dto.SortRequest) *domain.Sort {
-	sortBy := domain.SortBy(*req.SortBy)
-	return &domain.Sort{
-		SortBy: &sortBy,
+// fromSortRequest orders searches by relevance unless asked otherwise.
+func fromSortRequest(req dto.SortRequest, text string) (*domain.Sort, error) {
+	sort := &domain.Sort{}
+	if req.SortBy != nil {
+		sort.SortBy = domain.SortBy(*req.SortBy)
+	}
+	switch {
+	case sort.SortBy == "" && text != "":
+		sort.SortBy = domain.SortByRelevance
+	case sort.SortBy == domain.SortByRelevance && text == "":
+		return nil, domain.ErrSearchQueryRequired
 	}
+	return sort, nil
 }
 
+// cursorQuery is the digest a cursor carries of the search it was issued for.
+// Only relevance cursors are bound to their query; the other sort keys mean
+// the same in any search.
+func cursorQuery(sort *domain.Sort, text string) string {
+	if sort.SortBy != domain.SortByRelevance {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(textnorm.Fold(text)))
+	return base64.RawURLEncoding.EncodeToString(sum[:9])
+}
+
+// fromCursorPaginationRequest decodes the cursor, which must have been issued
+// for the same sort order and query as the request.
+func (s *ProductService) fromCursorPaginationRequest(
+	req dto.CursorPaginationRequest,
+	sort *domain.Sort,
+	query string,
+) (*domain.CursorPagination, error) {
+	res := &domain.CursorPagination{Limit: defaultListLimit}
+	if req.Limit != nil {
+		res.Limit = *req.Limit
+	}
+	if req.Cursor == nil || *req.Cursor == "" {
+		return res, nil
+	}
+
+	var cursor domain.Cursor
+	if err := s.cursors.Decode(*req.Cursor, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.SortBy != sort.SortBy {
+		return nil, domain.ErrCursorSortMismatch
+	}
+	if cursor.Query != query {
+		return nil, domain.ErrCursorQueryMismatch
+	}
+	res.Cursor = &cursor
+	return res, nil
+}
+
+func (s *ProductService) encodeCursor(cursor *domain.Cursor, query string) (*string, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+	cursor.Query = query
+	token, err := s.cursors.Encode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListResult is a page of products with opaque cursors for the next and
+// previous pages, nil where there is none. Facets is only set when asked for.
+type ListResult struct {
+	Products   []*domain.Product
+	NextCursor *string
+	PrevCursor *string
+	Facets     *domain.Facets
+}
+
+// List returns a page of products, searched when the request carries text.
 func (s *ProductService) List(
 	ctx context.Context,
 	filterRequest dto.ListFilterRequest,
 	paginationRequest dto.CursorPaginationRequest,
 	sortRequest dto.SortRequest,
-) ([]*domain.Product, *string, error) {
+) (*ListResult, error) {
 	filter := fromListFilterRequest(filterRequest)
-	pagination := fromCursorPaginationRequest(paginationRequest)
-	sort := fromSortRequest(sortRequest)
-	return s.repo.List(ctx, filter, pagination, sort)
+	if len(filter.Categories) > 0 {
+		expanded, err := s.categories.ExpandCategories(ctx, filter.Categories)
+		if err != nil {
+			return nil, err
+		}
+		// The references are kept as well: legacy free-text values match
+		// the products stored with them, and unknown IDs match nothing
+		// rather than dropping the filter.
+		filter.Categories = append(expanded, filter.Categories...)
+	}
+	text := searchText(filterRequest)
+	sort, err := fromSortRequest(sortRequest, text)
+	if err != nil {
+		return nil, err
+	}
+	query := cursorQuery(sort, text)
+	pagination, err := s.fromCursorPaginationRequest(paginationRequest, sort, query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ListResult{}
+	var page *domain.Page
+	if text != "" {
+		res.Products, page, err = s.searcher.Search(ctx, text, filter, pagination, sort)
+	} else {
+		res.Products, page, err = s.repo.List(ctx, filter, pagination, sort)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res.NextCursor, err = s.encodeCursor(page.Next, query); err != nil {
+		return nil, err
+	}
+	if res.PrevCursor, err = s.encodeCursor(page.Prev, query); err != nil {
+		return nil, err
+	}
+
+	if filterRequest.Facets {
+		if text != "" {
+			res.Facets, err = s.searcher.Facets(ctx, text, filter)
+		} else {
+			res.Facets, err = s.repo.Facets(ctx, filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := s.labelCategoryFacets(ctx, res.Facets); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// labelCategoryFacets names the category facet values, which are IDs, and
+// drops the IDs that no longer name a category. Legacy free-text values are
+// their own label.
+func (s *ProductService) labelCategoryFacets(ctx context.Context, facets *domain.Facets) error {
+	ids := make([]string, 0, len(facets.Categories))
+	for _, count := range facets.Categories {
+		ids = append(ids, count.Value)
+	}
+	names, slugs, err := s.categories.CategoryLabels(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	labelled := make([]domain.FacetCount, 0, len(facets.Categories))
+	for _, count := range facets.Categories {
+		if name, ok := names[count.Value]; ok {
+			count.Name, count.Slug = name, slugs[count.Value]
+		} else if domain.IsCategoryID(count.Value) {
+			continue
+		} else {
+			count.Name = count.Value
+		}
+		labelled = append(labelled, count)
+	}
+	facets.Categories = labelled
+	return nil
 }
 
 func (s *ProductService) AddReview(
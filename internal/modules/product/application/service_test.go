@@ -0,0 +1,193 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/product/delivery/http/dto"
+	"bobshop/internal/modules/product/domain"
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/pagination"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSearchText(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.ListFilterRequest
+		want string
+	}{
+		{name: "listing", want: ""},
+		{name: "query", req: dto.ListFilterRequest{Query: ptr("  áo đỏ ")}, want: "áo đỏ"},
+		{name: "legacy name", req: dto.ListFilterRequest{Name: ptr("shirt")}, want: "shirt"},
+		{name: "query wins over name", req: dto.ListFilterRequest{Query: ptr("shirt"), Name: ptr("hat")}, want: "shirt"},
+		{name: "blank query", req: dto.ListFilterRequest{Query: ptr("   ")}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchText(tt.req); got != tt.want {
+				t.Errorf("searchText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromSortRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		sortBy  *string
+		text    string
+		want    domain.SortBy
+		wantErr error
+	}{
+		{name: "listing keeps the default order", want: ""},
+		{name: "search defaults to relevance", text: "shirt", want: domain.SortByRelevance},
+		{name: "search can sort by price", sortBy: ptr("price_asc"), text: "shirt", want: domain.SortByPriceAsc},
+		{name: "listing can sort by price", sortBy: ptr("price_desc"), want: domain.SortByPriceDesc},
+		{name: "relevance needs a query", sortBy: ptr("relevance"), wantErr: domain.ErrSearchQueryRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fromSortRequest(dto.SortRequest{SortBy: tt.sortBy}, tt.text)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("fromSortRequest() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.SortBy != tt.want {
+				t.Errorf("fromSortRequest() = %q, want %q", got.SortBy, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorBinding(t *testing.T) {
+	cursors, err := pagination.NewCursorCodec(&config.PaginationConfig{CursorSecret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ProductService{cursors: cursors}
+	relevance := &domain.Sort{SortBy: domain.SortByRelevance}
+	price := &domain.Sort{SortBy: domain.SortByPriceAsc}
+
+	issue := func(sort *domain.Sort, text string) *string {
+		t.Helper()
+		token, err := s.encodeCursor(&domain.Cursor{SortBy: sort.SortBy, ID: uuid.New()}, cursorQuery(sort, text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		cursor  *string
+		sort    *domain.Sort
+		text    string
+		wantErr error
+	}{
+		{name: "same query", cursor: issue(relevance, "áo đỏ"), sort: relevance, text: "áo đỏ"},
+		{name: "same query folded", cursor: issue(relevance, "Áo  Đỏ"), sort: relevance, text: "ao do"},
+		{
+			name:    "other query",
+			cursor:  issue(relevance, "áo đỏ"),
+			sort:    relevance,
+			text:    "giày",
+			wantErr: domain.ErrCursorQueryMismatch,
+		},
+		{name: "price cursor across queries", cursor: issue(price, "áo"), sort: price, text: "giày"},
+		{name: "other sort", cursor: issue(price, "áo"), sort: relevance, text: "áo", wantErr: domain.ErrCursorSortMismatch},
+		{name: "tampered", cursor: ptr("e30.AAAA"), sort: relevance, text: "áo", wantErr: pagination.ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := dto.CursorPaginationRequest{Cursor: tt.cursor}
+			got, err := s.fromCursorPaginationRequest(req, tt.sort, cursorQuery(tt.sort, tt.text))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("fromCursorPaginationRequest() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Cursor == nil {
+				t.Error("fromCursorPaginationRequest() dropped the cursor")
+			}
+		})
+	}
+}
+
+type stubCatalog struct {
+	domain.CategoryCatalog
+	names map[string]string
+	slugs map[string]string
+	calls int
+}
+
+func (c *stubCatalog) CategoryLabels(_ context.Context, ids []string) (map[string]string, map[string]string, error) {
+	c.calls++
+	return pick(c.names, ids), pick(c.slugs, ids), nil
+}
+
+func pick(m map[string]string, keys []string) map[string]string {
+	res := map[string]string{}
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func TestLabelCategoryFacets(t *testing.T) {
+	shoes, hats, gone := uuid.NewString(), uuid.NewString(), uuid.NewString()
+
+	tests := []struct {
+		name   string
+		counts []domain.FacetCount
+		want   []domain.FacetCount
+	}{
+		{name: "none", counts: []domain.FacetCount{}, want: []domain.FacetCount{}},
+		{
+			name:   "labelled in order",
+			counts: []domain.FacetCount{{Value: hats, Count: 4}, {Value: shoes, Count: 2}},
+			want: []domain.FacetCount{
+				{Value: hats, Name: "Mũ", Slug: "mu", Count: 4},
+				{Value: shoes, Name: "Giày dép", Slug: "giay-dep", Count: 2},
+			},
+		},
+		{
+			name:   "deleted category dropped",
+			counts: []domain.FacetCount{{Value: gone, Count: 7}, {Value: shoes, Count: 2}},
+			want:   []domain.FacetCount{{Value: shoes, Name: "Giày dép", Slug: "giay-dep", Count: 2}},
+		},
+		{
+			name:   "legacy name kept",
+			counts: []domain.FacetCount{{Value: "Giày thể thao", Count: 3}, {Value: shoes, Count: 2}},
+			want: []domain.FacetCount{
+				{Value: "Giày thể thao", Name: "Giày thể thao", Count: 3},
+				{Value: shoes, Name: "Giày dép", Slug: "giay-dep", Count: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := &stubCatalog{
+				names: map[string]string{shoes: "Giày dép", hats: "Mũ"},
+				slugs: map[string]string{shoes: "giay-dep", hats: "mu"},
+			}
+			s := &ProductService{categories: catalog}
+			facets := &domain.Facets{Categories: tt.counts}
+			if err := s.labelCategoryFacets(context.Background(), facets); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(facets.Categories, tt.want) {
+				t.Errorf("categories = %+v, want %+v", facets.Categories, tt.want)
+			}
+			if catalog.calls != 1 {
+				t.Errorf("looked categories up %d times, want once", catalog.calls)
+			}
+		})
+	}
+}
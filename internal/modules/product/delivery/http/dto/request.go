@@ -1,13 +1,15 @@
 package dto
 
 type CreateRequest struct {
-	Name  string `json:"name" validate:"required"`
-	Price uint32 `json:"price" validate:"required"`
+	Name       string   `json:"name" validate:"required"`
+	Price      uint32   `json:"price" validate:"required"`
+	Categories []string `json:"categories" validate:"omitempty,dive,uuid"`
 }
 
 type UpdateRequest struct {
-	Name  *string `json:"name" validate:"omitempty"`
-	Price *uint32 `json:"price" validate:"omitempty"`
+	Name       *string  `json:"name" validate:"omitempty"`
+	Price      *uint32  `json:"price" validate:"omitempty"`
+	Categories []string `json:"categories" validate:"omitempty,dive,uuid"`
 }
 
 type AddReviewRequest struct {
@@ -16,20 +18,30 @@ type AddReviewRequest struct {
 }
 
 type ListFilterRequest struct {
-	Name       *string  `query:"name" validate:"omitempty"`
-	Categories []string `query:"categories" validate:"omitempty,dive,required"`
-	Brands     []string `query:"brands" validate:"omitempty,dive,required"`
-	Vendor     *string  `query:"vendor" validate:"omitempty"`
-	Tags       []string `query:"tags" validate:"omitempty,dive,required"`
-	MinPrice   *uint32  `query:"min_price" validate:"omitempty"`
-	MaxPrice   *uint32  `query:"max_price" validate:"omitempty"`
+	Query *string `form:"q" validate:"omitempty,max=200"`
+	// Name is the former name filter, now searched like Query.
+	Name *string `form:"name" validate:"omitempty,max=200"`
+	// Categories takes category IDs or slugs and includes their descendants.
+	Categories []string `form:"categories" validate:"omitempty,dive,required"`
+	Brands     []string `form:"brands" validate:"omitempty,dive,required"`
+	Vendor     *string  `form:"vendor" validate:"omitempty"`
+	Tags       []string `form:"tags" validate:"omitempty,dive,required"`
+	MinPrice   *uint32  `form:"min_price" validate:"omitempty"`
+	MaxPrice   *uint32  `form:"max_price" validate:"omitempty"`
+	// Facets asks for the filter sidebar counts along with the page.
+	Facets bool `form:"facets"`
 }
 
 type CursorPaginationRequest struct {
-	Cursor *string `query:"cursor" validate:"omitempty,base64"`
-	Limit  *int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor *string `form:"cursor" validate:"omitempty,max=512"`
+	Limit  *int    `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type SuggestRequest struct {
+	Query string `form:"q" validate:"required,max=100"`
+	Limit *int   `form:"limit" validate:"omitempty,min=1,max=20"`
 }
 
 type SortRequest struct {
-	SortBy *string `query:"sort" validate:"omitempty,oneof=price_asc price_desc latest popular"`
+	SortBy *string `form:"sort" validate:"omitempty,oneof=price_asc price_desc latest popular relevance"`
 }
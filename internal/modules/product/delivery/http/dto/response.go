@@ -31,15 +31,79 @@ func ToProductResponse(p *domain.Product) *ProductResponse {
 type ListResponse struct {
 	Products   []*ProductResponse `json:"products"`
 	NextCursor *string            `json:"next_cursor"`
+	PrevCursor *string            `json:"prev_cursor"`
+	Facets     *FacetsResponse    `json:"facets,omitempty"`
 }
 
-func ToListResponse(ps []*domain.Product, nextCursor *string) *ListResponse {
-	var products []*ProductResponse
+func ToListResponse(ps []*domain.Product, nextCursor, prevCursor *string, facets *domain.Facets) *ListResponse {
+	products := make([]*ProductResponse, 0, len(ps))
 	for _, p := range ps {
 		products = append(products, ToProductResponse(p))
 	}
 	return &ListResponse{
 		Products:   products,
 		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Facets:     ToFacetsResponse(facets),
 	}
 }
+
+type SuggestionResponse struct {
+	Kind      string     `json:"kind"`
+	Text      string     `json:"text"`
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+}
+
+func ToSuggestionResponses(suggestions []domain.Suggestion) []*SuggestionResponse {
+	res := make([]*SuggestionResponse, 0, len(suggestions))
+	for _, s := range suggestions {
+		res = append(res, &SuggestionResponse{Kind: string(s.Kind), Text: s.Text, ProductID: s.ProductID})
+	}
+	return res
+}
+
+type FacetsResponse struct {
+	Categories []FacetCountResponse  `json:"categories"`
+	Brands     []FacetCountResponse  `json:"brands"`
+	Vendors    []FacetCountResponse  `json:"vendors"`
+	Tags       []FacetCountResponse  `json:"tags"`
+	Prices     []PriceBucketResponse `json:"prices"`
+}
+
+type FacetCountResponse struct {
+	Value string `json:"value"`
+	Name  string `json:"name,omitempty"`
+	Slug  string `json:"slug,omitempty"`
+	Count int    `json:"count"`
+}
+
+type PriceBucketResponse struct {
+	Min   uint32  `json:"min"`
+	Max   *uint32 `json:"max"`
+	Count int     `json:"count"`
+}
+
+func ToFacetsResponse(f *domain.Facets) *FacetsResponse {
+	if f == nil {
+		return nil
+	}
+	res := &FacetsResponse{
+		Categories: toFacetCountResponses(f.Categories),
+		Brands:     toFacetCountResponses(f.Brands),
+		Vendors:    toFacetCountResponses(f.Vendors),
+		Tags:       toFacetCountResponses(f.Tags),
+		Prices:     make([]PriceBucketResponse, 0, len(f.Prices)),
+	}
+	for _, b := range f.Prices {
+		res.Prices = append(res.Prices, PriceBucketResponse{Min: b.Min, Max: b.Max, Count: b.Count})
+	}
+	return res
+}
+
+func toFacetCountResponses(counts []domain.FacetCount) []FacetCountResponse {
+	res := make([]FacetCountResponse, 0, len(counts))
+	for _, c := range counts {
+		res = append(res, FacetCountResponse{Value: c.Value, Name: c.Name, Slug: c.Slug, Count: c.Count})
+	}
+	return res
+}
@@ -11,6 +11,7 @@ import (
 	"bobshop/internal/modules/product/application"
 	"bobshop/internal/modules/product/delivery/http/dto"
 	"bobshop/internal/modules/product/domain"
+	"bobshop/internal/platform/pagination"
 	"bobshop/internal/platform/response"
 	"bobshop/internal/platform/web"
 )
@@ -36,6 +37,10 @@ func (h *ProductHandler) Create(c *gin.Context) {
 
 	product, err := h.service.Create(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, domain.ErrUnknownCategory) {
+			response.BadRequest(c, "unknown category", err)
+			return
+		}
 		response.InternalError(c, err)
 		return
 	}
@@ -61,6 +66,10 @@ func (h *ProductHandler) Update(c *gin.Context) {
 			response.NotFound(c, err)
 			return
 		}
+		if errors.Is(err, domain.ErrUnknownCategory) {
+			response.BadRequest(c, "unknown category", err)
+			return
+		}
 		response.InternalError(c, err)
 		return
 	}
@@ -114,8 +123,8 @@ func (h *ProductHandler) List(c *gin.Context) {
 		return
 	}
 
-	var pagination dto.CursorPaginationRequest
-	if err := web.BindAndValidate(c, h.validate, &pagination); err != nil {
+	var page dto.CursorPaginationRequest
+	if err := web.BindAndValidate(c, h.validate, &page); err != nil {
 		response.BadRequest(c, "invalid pagination fields", err)
 		return
 	}
@@ -126,13 +135,41 @@ func (h *ProductHandler) List(c *gin.Context) {
 		return
 	}
 
-	products, nextCursor, err := h.service.List(c.Request.Context(), filter, pagination, sort)
+	res, err := h.service.List(c.Request.Context(), filter, page, sort)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) ||
+			errors.Is(err, domain.ErrCursorSortMismatch) ||
+			errors.Is(err, domain.ErrCursorQueryMismatch) {
+			response.BadRequest(c, "invalid cursor", err)
+			return
+		}
+		if errors.Is(err, domain.ErrSearchQueryRequired) {
+			response.BadRequest(c, "invalid sort fields", err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Products listed",
+		dto.ToListResponse(res.Products, res.NextCursor, res.PrevCursor, res.Facets),
+	)
+}
+
+func (h *ProductHandler) Suggest(c *gin.Context) {
+	var req dto.SuggestRequest
+	if err := web.BindAndValidate(c, h.validate, &req); err != nil {
+		response.BadRequest(c, "invalid fields", err)
+		return
+	}
+
+	suggestions, err := h.service.Suggest(c.Request.Context(), req)
 	if err != nil {
 		response.InternalError(c, err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Products listed", dto.ToListResponse(products, nextCursor))
+	response.Success(c, http.StatusOK, "Suggestions", dto.ToSuggestionResponses(suggestions))
 }
 
 func (h *ProductHandler) AddReview(c *gin.Context) {
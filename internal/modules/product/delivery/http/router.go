@@ -2,20 +2,30 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/security"
 )
 
 func RegisterRoutes(group *gin.RouterGroup, authMiddleware gin.HandlerFunc, h *ProductHandler) {
 	products := group.Group("/products")
 	{
-		admin := products.Group("", authMiddleware)
+		admin := products.Group("", authMiddleware, middleware.RequirePermission(security.PermProductWrite))
 		admin.POST("", h.Create)
 		admin.PUT("/:id", h.Update)
 		admin.DELETE("/:id", h.Delete)
 
+		products.GET("/suggest", h.Suggest)
 		products.GET("/:id", h.GetByID)
 		products.GET("", h.List)
-		products.POST("/:id/reviews", h.AddReview)
-		products.POST("/:id/view", h.TrackRecentlyViewed)
-		products.GET("/recently-viewed", h.GetRecentlyViewed)
+
+		user := products.Group("", authMiddleware, middleware.RequireUser())
+		user.POST("/:id/reviews",
+			middleware.RequirePermission(security.PermReviewWrite),
+			middleware.RequireVerifiedEmail(),
+			h.AddReview,
+		)
+		user.POST("/:id/view", h.TrackRecentlyViewed)
+		user.GET("/recently-viewed", h.GetRecentlyViewed)
 	}
 }
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/middleware"
+	"bobshop/internal/platform/security"
+	"bobshop/internal/platform/web"
+)
+
+func TestAdminRoutesForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.NewString()
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		role      string
+		principal *security.Principal
+	}{
+		{name: "customer creates", method: http.MethodPost, path: "/products", role: security.RoleUser},
+		{name: "customer updates", method: http.MethodPut, path: "/products/" + id, role: security.RoleUser},
+		{name: "customer deletes", method: http.MethodDelete, path: "/products/" + id, role: security.RoleUser},
+		{name: "no role", method: http.MethodPost, path: "/products"},
+		{
+			name:      "api key without scope",
+			method:    http.MethodDelete,
+			path:      "/products/" + id,
+			principal: &security.Principal{Name: "feed", Scopes: []security.Permission{security.PermCategoryWrite}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMiddleware := func(c *gin.Context) {
+				c.Set(web.UserIDKey, uuid.NewString())
+				c.Set(web.RoleKey, tt.role)
+				if tt.principal != nil {
+					c.Set(web.PrincipalKey, tt.principal)
+				}
+			}
+			engine := gin.New()
+			RegisterRoutes(engine.Group(""), authMiddleware, &ProductHandler{})
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+type rejectingTokenizer struct{ security.Tokenizer }
+
+func (rejectingTokenizer) ParseToken(string) (map[string]any, error) {
+	return nil, errors.New("no token")
+}
+
+func TestUserRoutesUnauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "review", method: http.MethodPost, path: "/products/" + id + "/reviews"},
+		{name: "track view", method: http.MethodPost, path: "/products/" + id + "/view"},
+		{name: "recently viewed", method: http.MethodGet, path: "/products/recently-viewed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMiddleware := middleware.AuthMiddleware(rejectingTokenizer{}, nil, nil, &config.AuthConfig{})
+			engine := gin.New()
+			RegisterRoutes(engine.Group(""), authMiddleware, &ProductHandler{})
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
@@ -9,4 +9,5 @@ import (
 type Cache interface {
 	TrackRecentlyViewed(ctx context.Context, userID uuid.UUID, productID uuid.UUID) error
 	GetRecentlyViewed(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ClearRecentlyViewed(ctx context.Context, userID uuid.UUID) error
 }
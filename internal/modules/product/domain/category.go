@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CategoryCatalog is what products need from the category tree. Products refer
+// to categories by ID.
+type CategoryCatalog interface {
+	// MissingCategories returns the IDs that name no category.
+	MissingCategories(ctx context.Context, ids []string) ([]string, error)
+	// ExpandCategories resolves IDs or slugs to the IDs of those categories
+	// and all of their descendants.
+	ExpandCategories(ctx context.Context, refs []string) ([]string, error)
+	// CategoryLabels maps category IDs to their names and slugs, leaving out
+	// IDs that name no category.
+	CategoryLabels(ctx context.Context, ids []string) (names, slugs map[string]string, err error)
+}
+
+// IsCategoryID reports whether a product's category value is an ID. Products
+// stored before the category tree hold free-text names instead, which are
+// shown as they are until the product is saved with IDs.
+func IsCategoryID(value string) bool {
+	_, err := uuid.Parse(value)
+	return err == nil
+}
@@ -6,4 +6,8 @@ var (
 	ErrProductNotFound     = errors.New("product not found")
 	ErrInvalidProduct      = errors.New("invalid product")
 	ErrReviewAlreadyExists = errors.New("review already exists")
+	ErrCursorSortMismatch  = errors.New("cursor belongs to a different sort order")
+	ErrCursorQueryMismatch = errors.New("cursor belongs to a different search query")
+	ErrSearchQueryRequired = errors.New("relevance sort requires a search query")
+	ErrUnknownCategory     = errors.New("unknown category")
 )
@@ -0,0 +1,36 @@
+package domain
+
+// MaxFacetValues caps how many values a facet lists, most common first.
+const MaxFacetValues = 20
+
+// PriceBucketBounds are the lower bounds of the price buckets counted by the
+// price facet; the last bucket is open-ended.
+var PriceBucketBounds = []uint32{0, 100_000, 250_000, 500_000, 1_000_000, 2_500_000, 5_000_000}
+
+// Facets counts the products matching a filter by each filterable field. Each
+// facet ignores the filter on its own field, so a shopper sees what else they
+// could pick: choosing one brand still shows the counts of the other brands.
+type Facets struct {
+	Categories []FacetCount
+	Brands     []FacetCount
+	Vendors    []FacetCount
+	Tags       []FacetCount
+	Prices     []PriceBucket
+}
+
+// FacetCount counts the products with a value. Category values are IDs, so
+// they also carry the category's Name and Slug.
+type FacetCount struct {
+	Value string
+	Name  string
+	Slug  string
+	Count int
+}
+
+// PriceBucket counts products priced from Min up to, but excluding, Max. Max
+// is nil for the last bucket.
+type PriceBucket struct {
+	Min   uint32
+	Max   *uint32
+	Count int
+}
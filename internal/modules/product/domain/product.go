@@ -38,6 +38,8 @@ type Product struct {
 	Name           string     `bson:"name" json:"name" validate:"required"`
 	Reviews        []*Review  `bson:"reviews" json:"reviews" validate:"dive"`
 	Categories     []string   `bson:"categories" json:"categories" validate:"dive,required"`
+	// Score is the text relevance of a search result; it is never stored.
+	Score float64 `bson:"score,omitempty" json:"-"`
 }
 
 type ProductBuilder struct {
@@ -62,6 +64,11 @@ func (b *ProductBuilder) WithDesc(desc string) *ProductBuilder {
 	return b
 }
 
+func (b *ProductBuilder) WithCategories(categories []string) *ProductBuilder {
+	b.product.Categories = categories
+	return b
+}
+
 func (b *ProductBuilder) Build() *Product {
 	return b.product
 }
@@ -1,7 +1,8 @@
 package domain
 
+import "github.com/google/uuid"
+
 type ListFilter struct {
-	Name       *string  `validate:"omitempty"`
 	Categories []string `validate:"omitempty,dive,required"`
 	Brands     []string `validate:"omitempty,dive,required"`
 	Vendor     *string  `validate:"omitempty"`
@@ -11,12 +12,31 @@ type ListFilter struct {
 }
 
 type CursorPagination struct {
-	Cursor *string `validate:"omitempty,base64"`
-	Limit  *int    `validate:"omitempty,min=1,max=100"`
+	Cursor *Cursor
+	Limit  int `validate:"min=1,max=100"`
+}
+
+// Cursor is a position in a sorted listing: the sort key of a product and its
+// ID, which breaks ties between equal keys. A Backward cursor pages towards
+// the start of the listing. Scores only compare within one search, so a
+// relevance cursor also carries a digest of the query it was issued for.
+type Cursor struct {
+	SortBy   SortBy    `json:"s"`
+	Key      int64     `json:"k"`
+	Score    float64   `json:"sc,omitempty"`
+	Query    string    `json:"q,omitempty"`
+	ID       uuid.UUID `json:"id"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// Page holds the cursors of the neighbouring pages, nil where there is none.
+type Page struct {
+	Next *Cursor
+	Prev *Cursor
 }
 
 type Sort struct {
-	SortBy *SortBy `validate:"omitempty,oneof=price_asc price_desc latest popular"`
+	SortBy SortBy `validate:"omitempty,oneof=price_asc price_desc latest popular relevance"`
 }
 
 type SortBy string
@@ -26,4 +46,42 @@ const (
 	SortByPriceDesc SortBy = "price_desc"
 	SortByLatest    SortBy = "latest"
 	SortByPopular   SortBy = "popular"
+	// SortByRelevance orders search results by text score.
+	SortByRelevance SortBy = "relevance"
 )
+
+// Field returns the product field a listing is ordered by and whether the
+// order is descending. The default order has no field and runs by ID alone.
+func (s SortBy) Field() (field string, desc bool) {
+	switch s {
+	case SortByPriceAsc:
+		return "price", false
+	case SortByPriceDesc:
+		return "price", true
+	case SortByLatest:
+		return "created_at", true
+	case SortByPopular:
+		return "sales", true
+	case SortByRelevance:
+		return "score", true
+	default:
+		return "", false
+	}
+}
+
+// CursorAt returns the cursor pointing at p in this order. Times are kept in
+// milliseconds, the precision MongoDB stores them at.
+func (s SortBy) CursorAt(p *Product, backward bool) *Cursor {
+	cursor := &Cursor{SortBy: s, ID: p.ID, Backward: backward}
+	switch s {
+	case SortByPriceAsc, SortByPriceDesc:
+		cursor.Key = int64(p.Price)
+	case SortByLatest:
+		cursor.Key = p.CreatedAt.UnixMilli()
+	case SortByPopular:
+		cursor.Key = int64(p.Sales)
+	case SortByRelevance:
+		cursor.Score = p.Score
+	}
+	return cursor
+}
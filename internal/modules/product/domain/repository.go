@@ -13,5 +13,12 @@ type ProductRepository interface {
 	AddReview(ctx context.Context, review *Review) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Product, error)
-	List(ctx context.Context, filter *ListFilter, pagination *CursorPagination, sort *Sort) (products []*Product, nextCursor *string, err error)
+	// List pages through products by keyset on (sort key, _id), so pages
+	// neither skip nor repeat products under any sort order.
+	List(ctx context.Context, filter *ListFilter, pagination *CursorPagination, sort *Sort) ([]*Product, *Page, error)
+	Facets(ctx context.Context, filter *ListFilter) (*Facets, error)
+	ListReviewsByUser(ctx context.Context, userID uuid.UUID) ([]*Review, error)
+	// AnonymizeReviews detaches a user's reviews from them and drops the text.
+	// Ratings stay, so product star counts are unchanged.
+	AnonymizeReviews(ctx context.Context, userID uuid.UUID) error
 }
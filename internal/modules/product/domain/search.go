@@ -0,0 +1,18 @@
+package domain
+
+import "context"
+
+// Searcher finds products matching free text. Results are filtered and paged
+// like List; SortByRelevance orders them by how well they match.
+type Searcher interface {
+	Search(
+		ctx context.Context,
+		text string,
+		filter *ListFilter,
+		pagination *CursorPagination,
+		sort *Sort,
+	) ([]*Product, *Page, error)
+	// Facets counts the products matching text the way ProductRepository
+	// counts a listing.
+	Facets(ctx context.Context, text string, filter *ListFilter) (*Facets, error)
+}
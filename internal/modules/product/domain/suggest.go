@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 20
+)
+
+type SuggestionKind string
+
+const (
+	SuggestionProduct  SuggestionKind = "product"
+	SuggestionBrand    SuggestionKind = "brand"
+	SuggestionCategory SuggestionKind = "category"
+)
+
+// Suggestion completes what a shopper is typing. ProductID is only set for
+// product names.
+type Suggestion struct {
+	Kind      SuggestionKind `json:"k"`
+	Text      string         `json:"t"`
+	ProductID *uuid.UUID     `json:"id,omitempty"`
+}
+
+// SuggestIndex completes search box input from product names, brands and
+// categories, most popular first. It remembers what each product put in, so
+// indexing a product again replaces its entries and removing it takes back
+// exactly those, whatever it was indexed with.
+type SuggestIndex interface {
+	Index(ctx context.Context, productID uuid.UUID, suggestions []Suggestion, sales uint32) error
+	Remove(ctx context.Context, productID uuid.UUID) error
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
+}
+
+// Suggestions lists what a product contributes to the index. Categories are
+// shown by name and legacy free-text values as they are; IDs that name no
+// category are left out.
+func (p *Product) Suggestions(categoryNames map[string]string) []Suggestion {
+	var res []Suggestion
+	if p.Name != "" {
+		id := p.ID
+		res = append(res, Suggestion{Kind: SuggestionProduct, Text: p.Name, ProductID: &id})
+	}
+	if p.Brands != "" {
+		res = append(res, Suggestion{Kind: SuggestionBrand, Text: p.Brands})
+	}
+	for _, category := range p.Categories {
+		name, ok := categoryNames[category]
+		if !ok && !IsCategoryID(category) {
+			name = category
+		}
+		if name != "" {
+			res = append(res, Suggestion{Kind: SuggestionCategory, Text: name})
+		}
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestProductSuggestions(t *testing.T) {
+	id := uuid.New()
+	shoes, gone := uuid.NewString(), uuid.NewString()
+
+	tests := []struct {
+		name    string
+		product Product
+		names   map[string]string
+		want    []Suggestion
+	}{
+		{
+			name:    "name, brand and named category",
+			product: Product{ID: id, Name: "Giày", Brands: "Bob", Categories: []string{shoes}},
+			names:   map[string]string{shoes: "Giày dép"},
+			want: []Suggestion{
+				{Kind: SuggestionProduct, Text: "Giày", ProductID: &id},
+				{Kind: SuggestionBrand, Text: "Bob"},
+				{Kind: SuggestionCategory, Text: "Giày dép"},
+			},
+		},
+		{
+			name:    "unnamed categories are left out",
+			product: Product{ID: id, Name: "Giày", Categories: []string{shoes, gone}},
+			names:   map[string]string{shoes: "Giày dép"},
+			want: []Suggestion{
+				{Kind: SuggestionProduct, Text: "Giày", ProductID: &id},
+				{Kind: SuggestionCategory, Text: "Giày dép"},
+			},
+		},
+		{
+			name:    "legacy free-text category kept",
+			product: Product{ID: id, Categories: []string{"Giày thể thao", gone}},
+			want:    []Suggestion{{Kind: SuggestionCategory, Text: "Giày thể thao"}},
+		},
+		{
+			name:    "no names at all",
+			product: Product{ID: id, Categories: []string{shoes}},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.product.Suggestions(tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggestions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
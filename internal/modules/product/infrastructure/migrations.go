@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bobshop/internal/modules/product/domain"
+	"bobshop/internal/platform/database"
+)
+
+const (
+	productTextIndex = "product_search_text"
+	// legacyTextIndex indexed the raw fields; a collection holds one text index.
+	legacyTextIndex = "product_text"
+
+	indexNotFound = 27
+	backfillBatch = 500
+)
+
+// Migrations are run by cmd/migrate.
+var Migrations = []database.Migration{
+	{
+		ID:          "20261018_product_search_text",
+		Description: "index the folded product search text and build it for stored products",
+		Up:          indexSearchText,
+	},
+}
+
+// productTextWeights ranks a match in the name far above one in the body.
+var productTextWeights = bson.D{
+	{Key: "search_text.name", Value: 10},
+	{Key: "search_text.keywords", Value: 5},
+	{Key: "search_text.desc", Value: 2},
+	{Key: "search_text.content", Value: 1},
+}
+
+// indexSearchText swaps the text index over the raw fields for one over the
+// folded search documents, then builds the documents of products stored
+// before them. Products written since carry their own.
+func indexSearchText(ctx context.Context, db *mongo.Database) error {
+	products := db.Collection("products")
+	_, err := products.Indexes().DropOne(ctx, legacyTextIndex)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Code == indexNotFound) {
+		return err
+	}
+
+	keys := bson.D{}
+	for _, field := range productTextWeights {
+		keys = append(keys, bson.E{Key: field.Key, Value: "text"})
+	}
+	_, err = products.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: keys,
+		Options: options.Index().
+			SetName(productTextIndex).
+			SetWeights(productTextWeights).
+			SetDefaultLanguage("none"),
+	})
+	if err != nil {
+		return err
+	}
+	return backfillSearchText(ctx, products)
+}
+
+// backfillSearchText builds the search document of products stored without
+// one.
+func backfillSearchText(ctx context.Context, products *mongo.Collection) error {
+	cursor, err := products.Find(ctx, bson.M{"search_text": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		_, err := products.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+		return err
+	}
+	for cursor.Next(ctx) {
+		var product domain.Product
+		if err := cursor.Decode(&product); err != nil {
+			return err
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": product.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"search_text": searchDocumentOf(&product)}}))
+		if len(writes) == backfillBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
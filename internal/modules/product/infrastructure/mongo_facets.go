@@ -0,0 +1,152 @@
+package infrastructure
+
+import (
+	"context"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"bobshop/internal/modules/product/domain"
+)
+
+// valueFacets maps each facet counted by value to the product field behind it.
+var valueFacets = map[string]string{
+	"categories": "categories",
+	"brands":     "brands",
+	"vendors":    "vendor",
+	"tags":       "tags",
+}
+
+type facetBucket struct {
+	Value any `bson:"_id"`
+	Count int `bson:"count"`
+}
+
+type facetResult struct {
+	Categories []facetBucket `bson:"categories"`
+	Brands     []facetBucket `bson:"brands"`
+	Vendors    []facetBucket `bson:"vendors"`
+	Tags       []facetBucket `bson:"tags"`
+	Prices     []facetBucket `bson:"prices"`
+}
+
+func (r *MongoProductRepository) Facets(ctx context.Context, filter *domain.ListFilter) (*domain.Facets, error) {
+	return countFacets(ctx, r.collection, liveQuery(), filter)
+}
+
+func (s *MongoSearcher) Facets(ctx context.Context, text string, filter *domain.ListFilter) (*domain.Facets, error) {
+	match := liveQuery()
+	match["$text"] = textSearch(text)
+	return countFacets(ctx, s.collection, match, filter)
+}
+
+// countFacets narrows the collection with match once, then counts every facet
+// in a single $facet stage.
+func countFacets(
+	ctx context.Context,
+	collection *mongo.Collection,
+	match bson.M,
+	filter *domain.ListFilter,
+) (*domain.Facets, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: facetStages(filterQuery(filter))}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []facetResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &domain.Facets{}, nil
+	}
+	return toFacets(&results[0]), nil
+}
+
+// facetStages builds the sub-pipeline of every facet from the filter
+// conditions, each without the condition on its own field.
+func facetStages(conditions bson.M) bson.M {
+	facets := bson.M{}
+	for name, field := range valueFacets {
+		facets[name] = bson.A{
+			bson.M{"$match": without(conditions, field)},
+			bson.M{"$unwind": "$" + field},
+			bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{nil, ""}}}},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": domain.MaxFacetValues},
+		}
+	}
+	facets["prices"] = bson.A{
+		bson.M{"$match": without(conditions, "price")},
+		bson.M{"$bucket": bson.M{
+			"groupBy":    "$price",
+			"boundaries": priceBoundaries(),
+			"output":     bson.M{"count": bson.M{"$sum": 1}},
+		}},
+	}
+	return facets
+}
+
+func without(query bson.M, field string) bson.M {
+	res := make(bson.M, len(query))
+	for k, v := range query {
+		if k != field {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// priceBoundaries closes the last bucket above any uint32 price, since $bucket
+// needs an upper bound.
+func priceBoundaries() bson.A {
+	boundaries := bson.A{}
+	for _, bound := range domain.PriceBucketBounds {
+		boundaries = append(boundaries, int64(bound))
+	}
+	return append(boundaries, int64(math.MaxUint32)+1)
+}
+
+func toFacets(res *facetResult) *domain.Facets {
+	facets := &domain.Facets{
+		Categories: toFacetCounts(res.Categories),
+		Brands:     toFacetCounts(res.Brands),
+		Vendors:    toFacetCounts(res.Vendors),
+		Tags:       toFacetCounts(res.Tags),
+		Prices:     make([]domain.PriceBucket, 0, len(domain.PriceBucketBounds)),
+	}
+
+	counts := make(map[int64]int, len(res.Prices))
+	for _, bucket := range res.Prices {
+		if lower, ok := bucket.Value.(int64); ok {
+			counts[lower] = bucket.Count
+		}
+	}
+	// Empty buckets are left out by $bucket; list them so the ranges are stable.
+	for i, lower := range domain.PriceBucketBounds {
+		bucket := domain.PriceBucket{Min: lower, Count: counts[int64(lower)]}
+		if i+1 < len(domain.PriceBucketBounds) {
+			upper := domain.PriceBucketBounds[i+1]
+			bucket.Max = &upper
+		}
+		facets.Prices = append(facets.Prices, bucket)
+	}
+	return facets
+}
+
+func toFacetCounts(buckets []facetBucket) []domain.FacetCount {
+	counts := make([]domain.FacetCount, 0, len(buckets))
+	for _, bucket := range buckets {
+		if value, ok := bucket.Value.(string); ok {
+			counts = append(counts, domain.FacetCount{Value: value, Count: bucket.Count})
+		}
+	}
+	return counts
+}
@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"bobshop/internal/modules/product/domain"
+)
+
+func TestWithout(t *testing.T) {
+	query := bson.M{"brands": "a", "price": bson.M{"$gte": 1}}
+	tests := []struct {
+		name  string
+		field string
+		want  bson.M
+	}{
+		{name: "drops the field", field: "brands", want: bson.M{"price": bson.M{"$gte": 1}}},
+		{name: "absent field", field: "tags", want: query},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := without(query, tt.field); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("without() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if len(query) != 2 {
+		t.Errorf("without() changed its input: %v", query)
+	}
+}
+
+func TestFacetStagesIgnoreOwnFilter(t *testing.T) {
+	vendor := "acme"
+	minPrice := uint32(100)
+	conditions := filterQuery(&domain.ListFilter{
+		Categories: []string{"c1"},
+		Brands:     []string{"Acme"},
+		Vendor:     &vendor,
+		Tags:       []string{"sale"},
+		MinPrice:   &minPrice,
+	})
+	stages := facetStages(conditions)
+
+	tests := []struct {
+		facet string
+		field string
+	}{
+		{facet: "categories", field: "categories"},
+		{facet: "brands", field: "brands"},
+		{facet: "vendors", field: "vendor"},
+		{facet: "tags", field: "tags"},
+		{facet: "prices", field: "price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.facet, func(t *testing.T) {
+			pipeline, ok := stages[tt.facet].(bson.A)
+			if !ok || len(pipeline) == 0 {
+				t.Fatalf("no pipeline for facet %q", tt.facet)
+			}
+			match := pipeline[0].(bson.M)["$match"].(bson.M)
+			if _, ok := match[tt.field]; ok {
+				t.Errorf("%s facet filters on its own field: %v", tt.facet, match)
+			}
+			if len(match) != len(conditions)-1 {
+				t.Errorf("%s facet match = %v, want every other condition", tt.facet, match)
+			}
+		})
+	}
+}
+
+func TestToFacetsPriceBuckets(t *testing.T) {
+	bound := func(i int) *uint32 { return &domain.PriceBucketBounds[i] }
+	last := len(domain.PriceBucketBounds) - 1
+
+	got := toFacets(&facetResult{
+		Prices: []facetBucket{
+			{Value: int64(0), Count: 2},
+			{Value: int64(250_000), Count: 5},
+			{Value: int64(5_000_000), Count: 1},
+		},
+	}).Prices
+	if len(got) != len(domain.PriceBucketBounds) {
+		t.Fatalf("got %d price buckets, want %d", len(got), len(domain.PriceBucketBounds))
+	}
+
+	tests := []struct {
+		name string
+		i    int
+		want domain.PriceBucket
+	}{
+		{name: "first", i: 0, want: domain.PriceBucket{Min: 0, Max: bound(1), Count: 2}},
+		{name: "empty bucket kept", i: 1, want: domain.PriceBucket{Min: 100_000, Max: bound(2), Count: 0}},
+		{name: "counted", i: 2, want: domain.PriceBucket{Min: 250_000, Max: bound(3), Count: 5}},
+		{name: "last is open-ended", i: last, want: domain.PriceBucket{Min: 5_000_000, Count: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(got[tt.i], tt.want) {
+				t.Errorf("bucket %d = %+v, want %+v", tt.i, got[tt.i], tt.want)
+			}
+		})
+	}
+}
+
+func TestToFacetCountsSkipsNonStrings(t *testing.T) {
+	got := toFacetCounts([]facetBucket{{Value: "Acme", Count: 3}, {Value: nil, Count: 9}, {Value: int32(4), Count: 1}})
+	want := []domain.FacetCount{{Value: "Acme", Count: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toFacetCounts() = %v, want %v", got, want)
+	}
+}
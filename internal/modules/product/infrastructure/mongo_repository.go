@@ -2,7 +2,9 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,12 +26,12 @@ func NewMongoProductRepository(db *mongo.Database) *MongoProductRepository {
 	// indexModels := []mongo.IndexModel{
 	// 	{Keys: bson.D{{"slug", 1}}, Options: options.Index().SetUnique(true)},
 	// 	{Keys: bson.D{{"categories", 1}}},
-	// 	{Keys: bson.D{{"brandlist", 1}}},
+	// 	{Keys: bson.D{{"brands", 1}}},
 	// 	{Keys: bson.D{{"vendor", 1}}},
 	// 	{Keys: bson.D{{"tags", 1}}},
-	// 	{Keys: bson.D{{"price", 1}}},
-	// 	{Keys: bson.D{{"created_at", -1}}},
-	// 	{Keys: bson.D{{"sale", -1}}},
+	// 	{Keys: bson.D{{"price", 1}, {"_id", 1}}},
+	// 	{Keys: bson.D{{"created_at", -1}, {"_id", -1}}},
+	// 	{Keys: bson.D{{"sales", -1}, {"_id", -1}}},
 	// }
 	// _, err := collection.Indexes().CreateMany(context.Background(), indexModels)
 	// if err != nil {
@@ -39,23 +41,47 @@ func NewMongoProductRepository(db *mongo.Database) *MongoProductRepository {
 	return &MongoProductRepository{collection: collection}
 }
 
+// productDocument is a product as stored, with the search document the text
+// index covers.
+type productDocument struct {
+	*domain.Product `bson:",inline"`
+	SearchText      searchDocument `bson:"search_text"`
+}
+
 func (r *MongoProductRepository) Create(ctx context.Context, product *domain.Product) error {
-	_, err := r.collection.InsertOne(ctx, product)
+	_, err := r.collection.InsertOne(ctx, productDocument{Product: product, SearchText: searchDocumentOf(product)})
 	return err
 }
 
+// Update sets the fields, then rebuilds the search document from the updated
+// product when a searchable field changed.
 func (r *MongoProductRepository) Update(ctx context.Context, productID uuid.UUID, fields bson.M) error {
 	filter := bson.M{"_id": productID}
 	update := bson.M{"$set": fields}
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	var product domain.Product
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.ErrProductNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if result.MatchedCount == 0 {
-		return domain.ErrProductNotFound
+	if !touchesSearch(fields) {
+		return nil
 	}
-	return nil
+	_, err = r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"search_text": searchDocumentOf(&product)}})
+	return err
+}
+
+func touchesSearch(fields bson.M) bool {
+	for _, field := range searchFields {
+		if _, ok := fields[field]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *MongoProductRepository) AddReview(ctx context.Context, review *domain.Review) error {
@@ -104,79 +130,183 @@ func (r *MongoProductRepository) List(
 	filter *domain.ListFilter,
 	pagination *domain.CursorPagination,
 	sort *domain.Sort,
-) ([]*domain.Product, *string, error) {
-	query := bson.M{"deleted_at": nil, "is_active": true}
-
-	if filter != nil {
-		if filter.Name != nil {
-			query["name"] = bson.M{"$regex": *filter.Name, "$options": "i"}
-		}
-		if len(filter.Categories) > 0 {
-			query["categories"] = bson.M{"$in": filter.Categories}
-		}
-		if len(filter.Brands) > 0 {
-			query["brandlist"] = bson.M{"$in": filter.Brands}
-		}
-		if filter.Vendor != nil {
-			query["vendor"] = *filter.Vendor
-		}
-		if len(filter.Tags) > 0 {
-			query["tags"] = bson.M{"$in": filter.Tags}
-		}
-		if filter.MinPrice != nil {
-			query["price"] = bson.M{"$gte": *filter.MinPrice}
-		}
-		if filter.MaxPrice != nil {
-			if existing, ok := query["price"].(bson.M); ok {
-				existing["$lte"] = *filter.MaxPrice
-			} else {
-				query["price"] = bson.M{"$lte": *filter.MaxPrice}
-			}
-		}
-	}
-
-	opts := options.Find().SetLimit(int64(*pagination.Limit + 1)) // +1 for next cursor
-
-	var sortBson bson.D
-	switch *sort.SortBy {
-	case domain.SortByPriceAsc:
-		sortBson = bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}}
-	case domain.SortByPriceDesc:
-		sortBson = bson.D{{Key: "price", Value: -1}, {Key: "_id", Value: 1}}
-	case domain.SortByLatest:
-		sortBson = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}
-	case domain.SortByPopular:
-		sortBson = bson.D{{Key: "sale", Value: -1}, {Key: "_id", Value: 1}} // Assuming popular by sales
-	default:
-		sortBson = bson.D{{Key: "_id", Value: 1}}
-	}
-	opts.SetSort(sortBson)
-
+) ([]*domain.Product, *domain.Page, error) {
+	query := listQuery(filter)
+	sortBy := sortByOf(sort)
+	backward := pagination.Cursor != nil && pagination.Cursor.Backward
 	if pagination.Cursor != nil {
-		cursorID, err := uuid.Parse(*pagination.Cursor)
-		if err != nil {
-			return nil, nil, err
-		}
-		// For cursor, assume cursor is last _id, and sort includes _id
-		query["_id"] = bson.M{"$gte": cursorID}
+		query["$and"] = bson.A{keysetAfter(sortBy, pagination.Cursor)}
 	}
 
+	opts := options.Find().
+		SetLimit(int64(pagination.Limit + 1)). // +1 to tell whether another page follows
+		SetSort(keysetSort(sortBy, backward))
+
 	cursor, err := r.collection.Find(ctx, query, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var products []*domain.Product
+	products := []*domain.Product{}
 	if err = cursor.All(ctx, &products); err != nil {
 		return nil, nil, err
 	}
+	products, page := pageOf(products, sortBy, pagination)
+	return products, page, nil
+}
 
-	var nextCursor string
-	if len(products) > *pagination.Limit {
-		nextCursor = products[*pagination.Limit].ID.String()
-		products = products[:*pagination.Limit]
+// listQuery matches the live products that pass the filter.
+func listQuery(filter *domain.ListFilter) bson.M {
+	query := liveQuery()
+	for field, cond := range filterQuery(filter) {
+		query[field] = cond
 	}
+	return query
+}
 
-	return products, &nextCursor, nil
+func liveQuery() bson.M {
+	return bson.M{"deleted_at": nil, "is_active": true}
+}
+
+// filterQuery holds one condition per filtered field, keyed by field name.
+func filterQuery(filter *domain.ListFilter) bson.M {
+	query := bson.M{}
+	if filter == nil {
+		return query
+	}
+
+	if len(filter.Categories) > 0 {
+		query["categories"] = bson.M{"$in": filter.Categories}
+	}
+	if len(filter.Brands) > 0 {
+		query["brands"] = bson.M{"$in": filter.Brands}
+	}
+	if filter.Vendor != nil {
+		query["vendor"] = *filter.Vendor
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$in": filter.Tags}
+	}
+	if filter.MinPrice != nil {
+		query["price"] = bson.M{"$gte": *filter.MinPrice}
+	}
+	if filter.MaxPrice != nil {
+		if existing, ok := query["price"].(bson.M); ok {
+			existing["$lte"] = *filter.MaxPrice
+		} else {
+			query["price"] = bson.M{"$lte": *filter.MaxPrice}
+		}
+	}
+	return query
+}
+
+func sortByOf(sort *domain.Sort) domain.SortBy {
+	if sort == nil {
+		return ""
+	}
+	return sort.SortBy
+}
+
+// pageOf trims a result fetched with one extra product down to the page and
+// works out the cursors of its neighbours. Backward results arrive in reverse.
+func pageOf(
+	products []*domain.Product,
+	sortBy domain.SortBy,
+	pagination *domain.CursorPagination,
+) ([]*domain.Product, *domain.Page) {
+	backward := pagination.Cursor != nil && pagination.Cursor.Backward
+	more := len(products) > pagination.Limit
+	if more {
+		products = products[:pagination.Limit]
+	}
+	if backward {
+		slices.Reverse(products)
+	}
+
+	page := &domain.Page{}
+	if len(products) == 0 {
+		return products, page
+	}
+	// A backward page was reached from a later one, so there is always a next.
+	if more || backward {
+		page.Next = sortBy.CursorAt(products[len(products)-1], false)
+	}
+	if backward && more || !backward && pagination.Cursor != nil {
+		page.Prev = sortBy.CursorAt(products[0], true)
+	}
+	return products, page
+}
+
+// keysetSort orders by the sort key, then _id in the same direction, so the
+// order is total. Paging backward walks the same order in reverse.
+func keysetSort(sortBy domain.SortBy, backward bool) bson.D {
+	field, desc := sortBy.Field()
+	dir := 1
+	if desc != backward {
+		dir = -1
+	}
+	if field == "" {
+		return bson.D{{Key: "_id", Value: dir}}
+	}
+	return bson.D{{Key: field, Value: dir}, {Key: "_id", Value: dir}}
+}
+
+// keysetAfter matches the products that come after the cursor in the order
+// keysetSort walks: (key, _id) > (cursor key, cursor ID) as a tuple.
+func keysetAfter(sortBy domain.SortBy, cursor *domain.Cursor) bson.M {
+	field, desc := sortBy.Field()
+	op := "$gt"
+	if desc != cursor.Backward {
+		op = "$lt"
+	}
+	if field == "" {
+		return bson.M{"_id": bson.M{op: cursor.ID}}
+	}
+
+	var key any = cursor.Key
+	switch sortBy {
+	case domain.SortByLatest:
+		key = time.UnixMilli(cursor.Key).UTC()
+	case domain.SortByRelevance:
+		key = cursor.Score
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{op: key}},
+		bson.M{field: key, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+func (r *MongoProductRepository) ListReviewsByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Review, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"reviews.user_id": userID}}},
+		{{Key: "$unwind", Value: "$reviews"}},
+		{{Key: "$match", Value: bson.M{"reviews.user_id": userID}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$reviews"}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reviews := []*domain.Review{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *MongoProductRepository) AnonymizeReviews(ctx context.Context, userID uuid.UUID) error {
+	filter := bson.M{"reviews.user_id": userID}
+	update := bson.M{"$set": bson.M{
+		"reviews.$[review].user_id":    uuid.Nil,
+		"reviews.$[review].comment":    "",
+		"reviews.$[review].updated_at": time.Now(),
+	}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []any{bson.M{"review.user_id": userID}},
+	})
+	_, err := r.collection.UpdateMany(ctx, filter, update, opts)
+	return err
 }
@@ -0,0 +1,252 @@
+package infrastructure
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"bobshop/internal/modules/product/domain"
+)
+
+func TestKeysetSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		sortBy   domain.SortBy
+		backward bool
+		want     bson.D
+	}{
+		{name: "default", want: bson.D{{Key: "_id", Value: 1}}},
+		{name: "default backward", backward: true, want: bson.D{{Key: "_id", Value: -1}}},
+		{name: "price ascending", sortBy: domain.SortByPriceAsc, want: bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}}},
+		{
+			name:     "price ascending backward",
+			sortBy:   domain.SortByPriceAsc,
+			backward: true,
+			want:     bson.D{{Key: "price", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{name: "latest", sortBy: domain.SortByLatest, want: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{
+			name:     "popular backward",
+			sortBy:   domain.SortByPopular,
+			backward: true,
+			want:     bson.D{{Key: "sales", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{name: "relevance", sortBy: domain.SortByRelevance, want: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}},
+		{
+			name:     "relevance backward",
+			sortBy:   domain.SortByRelevance,
+			backward: true,
+			want:     bson.D{{Key: "score", Value: 1}, {Key: "_id", Value: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keysetSort(tt.sortBy, tt.backward); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetSort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetAfter(t *testing.T) {
+	id := uuid.New()
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tuple := func(field, op string, key any) bson.M {
+		return bson.M{"$or": bson.A{
+			bson.M{field: bson.M{op: key}},
+			bson.M{field: key, "_id": bson.M{op: id}},
+		}}
+	}
+
+	tests := []struct {
+		name   string
+		sortBy domain.SortBy
+		cursor domain.Cursor
+		want   bson.M
+	}{
+		{name: "default", cursor: domain.Cursor{ID: id}, want: bson.M{"_id": bson.M{"$gt": id}}},
+		{name: "default backward", cursor: domain.Cursor{ID: id, Backward: true}, want: bson.M{"_id": bson.M{"$lt": id}}},
+		{
+			name:   "price ascending",
+			sortBy: domain.SortByPriceAsc,
+			cursor: domain.Cursor{Key: 1999, ID: id},
+			want:   tuple("price", "$gt", int64(1999)),
+		},
+		{
+			name:   "price descending",
+			sortBy: domain.SortByPriceDesc,
+			cursor: domain.Cursor{Key: 1999, ID: id},
+			want:   tuple("price", "$lt", int64(1999)),
+		},
+		{
+			name:   "price descending backward",
+			sortBy: domain.SortByPriceDesc,
+			cursor: domain.Cursor{Key: 1999, ID: id, Backward: true},
+			want:   tuple("price", "$gt", int64(1999)),
+		},
+		{
+			name:   "latest compares times",
+			sortBy: domain.SortByLatest,
+			cursor: domain.Cursor{Key: created.UnixMilli(), ID: id},
+			want:   tuple("created_at", "$lt", created),
+		},
+		{
+			name:   "relevance compares scores",
+			sortBy: domain.SortByRelevance,
+			cursor: domain.Cursor{Score: 2.5, ID: id},
+			want:   tuple("score", "$lt", 2.5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keysetAfter(tt.sortBy, &tt.cursor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	products := make([]*domain.Product, 4)
+	for i := range products {
+		products[i] = &domain.Product{ID: uuid.New(), Price: uint32(100 * (i + 1))}
+	}
+	reversed := func(ps ...*domain.Product) []*domain.Product {
+		out := make([]*domain.Product, len(ps))
+		for i, p := range ps {
+			out[len(ps)-1-i] = p
+		}
+		return out
+	}
+	at := func(p *domain.Product, backward bool) *domain.Cursor {
+		return domain.SortByPriceAsc.CursorAt(p, backward)
+	}
+	forward := &domain.Cursor{ID: uuid.New()}
+	backward := &domain.Cursor{ID: uuid.New(), Backward: true}
+
+	tests := []struct {
+		name     string
+		fetched  []*domain.Product
+		cursor   *domain.Cursor
+		want     []*domain.Product
+		wantNext *domain.Cursor
+		wantPrev *domain.Cursor
+	}{
+		{name: "empty", fetched: []*domain.Product{}, want: []*domain.Product{}},
+		{
+			name:     "first page with more",
+			fetched:  products[:3],
+			want:     products[:2],
+			wantNext: at(products[1], false),
+		},
+		{
+			name:    "only page",
+			fetched: products[:2],
+			want:    products[:2],
+		},
+		{
+			name:     "middle page",
+			fetched:  products[1:4],
+			cursor:   forward,
+			want:     products[1:3],
+			wantNext: at(products[2], false),
+			wantPrev: at(products[1], true),
+		},
+		{
+			name:     "last page",
+			fetched:  products[2:4],
+			cursor:   forward,
+			want:     products[2:4],
+			wantPrev: at(products[2], true),
+		},
+		{
+			name:     "backward with more",
+			fetched:  reversed(products[0:3]...),
+			cursor:   backward,
+			want:     products[1:3],
+			wantNext: at(products[2], false),
+			wantPrev: at(products[1], true),
+		},
+		{
+			name:     "backward to the first page",
+			fetched:  reversed(products[0:2]...),
+			cursor:   backward,
+			want:     products[0:2],
+			wantNext: at(products[1], false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page := pageOf(append([]*domain.Product{}, tt.fetched...), domain.SortByPriceAsc, &domain.CursorPagination{
+				Cursor: tt.cursor,
+				Limit:  2,
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("products = %v, want %v", ids(got), ids(tt.want))
+			}
+			if !reflect.DeepEqual(page.Next, tt.wantNext) {
+				t.Errorf("next = %+v, want %+v", page.Next, tt.wantNext)
+			}
+			if !reflect.DeepEqual(page.Prev, tt.wantPrev) {
+				t.Errorf("prev = %+v, want %+v", page.Prev, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func ids(products []*domain.Product) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(products))
+	for _, p := range products {
+		out = append(out, p.ID)
+	}
+	return out
+}
+
+func TestFilterQuery(t *testing.T) {
+	vendor := "acme"
+	minPrice, maxPrice := uint32(100), uint32(500)
+
+	tests := []struct {
+		name   string
+		filter *domain.ListFilter
+		want   bson.M
+	}{
+		{name: "nil", want: bson.M{}},
+		{
+			name:   "brands",
+			filter: &domain.ListFilter{Brands: []string{"Acme", "Bob"}},
+			want:   bson.M{"brands": bson.M{"$in": []string{"Acme", "Bob"}}},
+		},
+		{
+			name: "every field",
+			filter: &domain.ListFilter{
+				Categories: []string{"c1"},
+				Vendor:     &vendor,
+				Tags:       []string{"sale"},
+				MinPrice:   &minPrice,
+				MaxPrice:   &maxPrice,
+			},
+			want: bson.M{
+				"categories": bson.M{"$in": []string{"c1"}},
+				"vendor":     "acme",
+				"tags":       bson.M{"$in": []string{"sale"}},
+				"price":      bson.M{"$gte": minPrice, "$lte": maxPrice},
+			},
+		},
+		{
+			name:   "max price only",
+			filter: &domain.ListFilter{MaxPrice: &maxPrice},
+			want:   bson.M{"price": bson.M{"$lte": maxPrice}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterQuery(tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
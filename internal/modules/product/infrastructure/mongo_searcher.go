@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"bobshop/internal/modules/product/domain"
+	"bobshop/pkg/textnorm"
+)
+
+// searchDocument is the folded copy of a product's searchable fields that the
+// text index covers. MongoDB ignores most diacritics but not the stroke in
+// "đ", so stored text and queries are both folded with textnorm.Fold.
+type searchDocument struct {
+	Name     string `bson:"name"`
+	Keywords string `bson:"keywords"`
+	Desc     string `bson:"desc"`
+	Content  string `bson:"content"`
+}
+
+// searchFields are the product fields a searchDocument is built from.
+var searchFields = []string{"name", "nameEng", "tags", "brands", "desc", "content"}
+
+func searchDocumentOf(p *domain.Product) searchDocument {
+	keywords := append(slices.Clone(p.Tags), p.Brands)
+	return searchDocument{
+		Name:     textnorm.Fold(p.Name + " " + p.NameEng),
+		Keywords: textnorm.Fold(strings.Join(keywords, " ")),
+		Desc:     textnorm.Fold(p.Desc),
+		Content:  textnorm.Fold(p.Content),
+	}
+}
+
+// MongoSearcher searches products through a MongoDB text index over their
+// folded search documents. The index uses no language, so Vietnamese and
+// English names are tokenised alike without stemming. cmd/migrate builds the
+// index.
+type MongoSearcher struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSearcher(db *mongo.Database) *MongoSearcher {
+	return &MongoSearcher{collection: db.Collection("products")}
+}
+
+func textSearch(text string) bson.M {
+	return bson.M{"$search": textnorm.Fold(text)}
+}
+
+func (s *MongoSearcher) Search(
+	ctx context.Context,
+	text string,
+	filter *domain.ListFilter,
+	pagination *domain.CursorPagination,
+	sort *domain.Sort,
+) ([]*domain.Product, *domain.Page, error) {
+	query := listQuery(filter)
+	query["$text"] = textSearch(text)
+
+	sortBy := sortByOf(sort)
+	backward := pagination.Cursor != nil && pagination.Cursor.Backward
+
+	// $text must lead the pipeline; the score only exists once it has run.
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: query}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if pagination.Cursor != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: keysetAfter(sortBy, pagination.Cursor)}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: keysetSort(sortBy, backward)}},
+		bson.D{{Key: "$limit", Value: pagination.Limit + 1}}, // +1 to tell whether another page follows
+	)
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := []*domain.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, nil, err
+	}
+	products, page := pageOf(products, sortBy, pagination)
+	return products, page, nil
+}
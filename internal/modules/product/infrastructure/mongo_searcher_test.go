@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"bobshop/internal/modules/product/domain"
+)
+
+func TestSearchDocumentOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		product domain.Product
+		want    searchDocument
+	}{
+		{
+			name: "folds vietnamese",
+			product: domain.Product{
+				Name:    "Áo Đỏ",
+				NameEng: "Red Shirt",
+				Tags:    []string{"Thời trang", "Nữ"},
+				Brands:  "Đông Á",
+				Desc:    "Vải  cotton",
+				Content: "Giặt máy",
+			},
+			want: searchDocument{
+				Name:     "ao do red shirt",
+				Keywords: "thoi trang nu dong a",
+				Desc:     "vai cotton",
+				Content:  "giat may",
+			},
+		},
+		{name: "empty", want: searchDocument{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchDocumentOf(&tt.product); got != tt.want {
+				t.Errorf("searchDocumentOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextSearch(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Đỏ", want: "do"},
+		{text: `"Áo  dài" -Đen`, want: `"ao dai" -den`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := textSearch(tt.text)["$search"]; got != tt.want {
+				t.Errorf("textSearch(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTouchesSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields bson.M
+		want   bool
+	}{
+		{name: "name", fields: bson.M{"name": "Áo", "updated_at": 1}, want: true},
+		{name: "tags", fields: bson.M{"tags": []string{"nữ"}}, want: true},
+		{name: "price only", fields: bson.M{"price": 100, "updated_at": 1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := touchesSearch(tt.fields); got != tt.want {
+				t.Errorf("touchesSearch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -49,3 +49,7 @@ func (r *RedisCache) GetRecentlyViewed(ctx context.Context, userID uuid.UUID) ([
 func (r *RedisCache) trimOldestViewed(ctx context.Context, key string, count uint32) error {
 	return r.client.ZRemRangeByRank(ctx, key, 0, int64(-count-1)).Err()
 }
+
+func (r *RedisCache) ClearRecentlyViewed(ctx context.Context, userID uuid.UUID) error {
+	return r.client.Del(ctx, buildUserRecentlyViewedKey(userID)).Err()
+}
@@ -0,0 +1,358 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	redis "github.com/redis/go-redis/v9"
+
+	"bobshop/internal/modules/product/domain"
+	"bobshop/pkg/textnorm"
+)
+
+const (
+	suggestPrefixKey = "suggest:prefix:%s"
+	suggestFuzzyKey  = "suggest:fuzzy:%s"
+	// suggestEntryKey records what a product put in the index.
+	suggestEntryKey = "suggest:product:%s"
+	// suggestRefsKey counts the products behind each brand and category.
+	suggestRefsKey = "suggest:refs"
+
+	maxSuggestPrefixLen = 20
+	// minFuzzyPrefixLen keeps typo matching off very short input, where one
+	// edit away matches almost anything.
+	minFuzzyPrefixLen = 4
+	// maxSuggestRetries bounds how often a replace is retried after losing a
+	// race with another change to the same product.
+	maxSuggestRetries = 5
+)
+
+var errSuggestConflict = errors.New("suggest: product entry kept changing")
+
+func buildSuggestPrefixKey(prefix string) string {
+	return fmt.Sprintf(suggestPrefixKey, prefix)
+}
+
+func buildSuggestFuzzyKey(prefix string) string {
+	return fmt.Sprintf(suggestFuzzyKey, prefix)
+}
+
+func buildSuggestEntryKey(productID uuid.UUID) string {
+	return fmt.Sprintf(suggestEntryKey, productID)
+}
+
+// suggestEntry is what a product put in the index and the sales it was
+// scored with.
+type suggestEntry struct {
+	Suggestions []domain.Suggestion `json:"s"`
+	Sales       uint32              `json:"n"`
+}
+
+// suggestOp files one member under its keys, given as 1-based KEYS indices.
+// Shared members, brands and categories, are reference counted and carry the
+// summed sales of their products.
+type suggestOp struct {
+	Member string  `json:"m"`
+	Shared bool    `json:"sh"`
+	Score  float64 `json:"sc"`
+	Keys   []int   `json:"k"`
+}
+
+type suggestPlan struct {
+	Remove []suggestOp `json:"remove"`
+	Add    []suggestOp `json:"add"`
+}
+
+// replaceScript applies a plan if the product's entry is still ARGV[1], then
+// records ARGV[2] as the entry, an empty one deleting it. It returns 0 without
+// touching anything when the entry changed since it was read. A shared member
+// is only dropped once no product carries it, and counts never go below zero.
+var replaceScript = redis.NewScript(`
+if (redis.call('GET', KEYS[1]) or '') ~= ARGV[1] then
+	return 0
+end
+local plan = cjson.decode(ARGV[3])
+for _, op in ipairs(plan.remove) do
+	local keep = false
+	if op.sh then
+		if redis.call('HINCRBY', KEYS[2], op.m, -1) > 0 then
+			keep = true
+		else
+			redis.call('HDEL', KEYS[2], op.m)
+		end
+	end
+	for _, k in ipairs(op.k) do
+		if keep then
+			if tonumber(redis.call('ZINCRBY', KEYS[k], -op.sc, op.m)) < 0 then
+				redis.call('ZADD', KEYS[k], 0, op.m)
+			end
+		else
+			redis.call('ZREM', KEYS[k], op.m)
+		end
+	end
+end
+for _, op in ipairs(plan.add) do
+	if op.sh then
+		redis.call('HINCRBY', KEYS[2], op.m, 1)
+	end
+	for _, k in ipairs(op.k) do
+		if op.sh then
+			redis.call('ZINCRBY', KEYS[k], op.sc, op.m)
+		else
+			redis.call('ZADD', KEYS[k], op.sc, op.m)
+		end
+	end
+end
+if ARGV[2] == '' then
+	redis.call('DEL', KEYS[1])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// RedisSuggestIndex keeps a sorted set per folded prefix, scored by sales. Typo
+// tolerance follows SymSpell: every prefix is also filed under each of its
+// one-character deletions, so input one edit away from a prefix shares a
+// deletion with it and is found with a handful of lookups.
+type RedisSuggestIndex struct {
+	client *redis.Client
+}
+
+func NewRedisSuggestIndex(client *redis.Client) *RedisSuggestIndex {
+	return &RedisSuggestIndex{client: client}
+}
+
+func (r *RedisSuggestIndex) Index(
+	ctx context.Context,
+	productID uuid.UUID,
+	suggestions []domain.Suggestion,
+	sales uint32,
+) error {
+	return r.replace(ctx, productID, &suggestEntry{Suggestions: suggestions, Sales: sales})
+}
+
+// Remove takes a product out of the index. Brands and categories stay while
+// other products still carry them, with the product's sales taken off. A
+// product that was never indexed is left alone.
+func (r *RedisSuggestIndex) Remove(ctx context.Context, productID uuid.UUID) error {
+	return r.replace(ctx, productID, nil)
+}
+
+// replace swaps the product's recorded entry for next, nil to drop it. The swap
+// is planned from the entry as read and applied in one script, which refuses it
+// if the entry changed in between; the swap is then planned again.
+func (r *RedisSuggestIndex) replace(ctx context.Context, productID uuid.UUID, next *suggestEntry) error {
+	entryKey := buildSuggestEntryKey(productID)
+	for range maxSuggestRetries {
+		prev, err := r.client.Get(ctx, entryKey).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		keys, args, err := replacePlan(entryKey, prev, next)
+		if err != nil {
+			return err
+		}
+		applied, err := replaceScript.Run(ctx, r.client, keys, args...).Int()
+		if err != nil {
+			return err
+		}
+		if applied == 1 {
+			return nil
+		}
+	}
+	return errSuggestConflict
+}
+
+// replacePlan lays out the script's KEYS and ARGV for swapping the recorded
+// entry prev, empty when there is none, for next.
+func replacePlan(entryKey, prev string, next *suggestEntry) ([]string, []any, error) {
+	keys := []string{entryKey, suggestRefsKey}
+	index := map[string]int{}
+	keyIndex := func(key string) int {
+		i, ok := index[key]
+		if !ok {
+			keys = append(keys, key)
+			i = len(keys)
+			index[key] = i
+		}
+		return i
+	}
+	ops := func(entry *suggestEntry) ([]suggestOp, error) {
+		res := []suggestOp{}
+		if entry == nil {
+			return res, nil
+		}
+		for _, suggestion := range entry.Suggestions {
+			member, err := json.Marshal(suggestion)
+			if err != nil {
+				return nil, err
+			}
+			op := suggestOp{
+				Member: string(member),
+				Shared: suggestion.Kind != domain.SuggestionProduct,
+				Score:  float64(entry.Sales),
+				Keys:   []int{},
+			}
+			for _, key := range suggestKeys(suggestion.Text) {
+				op.Keys = append(op.Keys, keyIndex(key))
+			}
+			res = append(res, op)
+		}
+		return res, nil
+	}
+
+	var prevEntry *suggestEntry
+	if prev != "" {
+		prevEntry = &suggestEntry{}
+		if err := json.Unmarshal([]byte(prev), prevEntry); err != nil {
+			return nil, nil, err
+		}
+	}
+	var (
+		plan suggestPlan
+		err  error
+	)
+	if plan.Remove, err = ops(prevEntry); err != nil {
+		return nil, nil, err
+	}
+	if plan.Add, err = ops(next); err != nil {
+		return nil, nil, err
+	}
+	encodedPlan, err := json.Marshal(plan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record := ""
+	if next != nil {
+		encoded, err := json.Marshal(next)
+		if err != nil {
+			return nil, nil, err
+		}
+		record = string(encoded)
+	}
+	return keys, []any{prev, record, string(encodedPlan)}, nil
+}
+
+// Suggest returns prefix matches first, then matches one edit away, each
+// ranked by sales.
+func (r *RedisSuggestIndex) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	query := truncateRunes(textnorm.Fold(prefix), maxSuggestPrefixLen)
+	if query == "" {
+		return []domain.Suggestion{}, nil
+	}
+
+	exactKey, fuzzyKeys := lookupKeys(query)
+	pipe := r.client.Pipeline()
+	exact := pipe.ZRevRangeWithScores(ctx, exactKey, 0, int64(limit-1))
+	var fuzzy []*redis.ZSliceCmd
+	for _, key := range fuzzyKeys {
+		fuzzy = append(fuzzy, pipe.ZRevRangeWithScores(ctx, key, 0, int64(limit-1)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	res := make([]domain.Suggestion, 0, limit)
+	res = appendSuggestions(res, seen, exact.Val(), limit)
+
+	var candidates []redis.Z
+	for _, cmd := range fuzzy {
+		candidates = append(candidates, cmd.Val()...)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return appendSuggestions(res, seen, candidates, limit), nil
+}
+
+// lookupKeys returns the key holding the prefix matches of a folded query and
+// the keys holding the texts one edit away: the query with a character
+// inserted is filed under the query's fuzzy key, with one replaced under the
+// fuzzy key of a deletion, and with one dropped under a deletion's prefix key.
+func lookupKeys(query string) (string, []string) {
+	var fuzzy []string
+	if runeLen(query) >= minFuzzyPrefixLen {
+		fuzzy = append(fuzzy, buildSuggestFuzzyKey(query))
+		for _, deletion := range deletions(query) {
+			fuzzy = append(fuzzy, buildSuggestPrefixKey(deletion), buildSuggestFuzzyKey(deletion))
+		}
+	}
+	return buildSuggestPrefixKey(query), fuzzy
+}
+
+func appendSuggestions(res []domain.Suggestion, seen map[string]bool, members []redis.Z, limit int) []domain.Suggestion {
+	for _, z := range members {
+		if len(res) >= limit {
+			break
+		}
+		member, ok := z.Member.(string)
+		if !ok || seen[member] {
+			continue
+		}
+		seen[member] = true
+		var suggestion domain.Suggestion
+		if err := json.Unmarshal([]byte(member), &suggestion); err != nil {
+			continue
+		}
+		res = append(res, suggestion)
+	}
+	return res
+}
+
+// suggestKeys lists the keys a text is filed under: the prefixes of every
+// word-initial phrase, so "ao thun" is found by "thu" too, and the deletions
+// of the longer prefixes.
+func suggestKeys(text string) []string {
+	words := []rune(textnorm.Fold(text))
+	seen := map[string]bool{}
+	var keys []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for start := range words {
+		if start > 0 && words[start-1] != ' ' {
+			continue
+		}
+		phrase := words[start:]
+		for n := 1; n <= len(phrase) && n <= maxSuggestPrefixLen; n++ {
+			prefix := string(phrase[:n])
+			add(buildSuggestPrefixKey(prefix))
+			if n >= minFuzzyPrefixLen {
+				for _, deletion := range deletions(prefix) {
+					add(buildSuggestFuzzyKey(deletion))
+				}
+			}
+		}
+	}
+	return keys
+}
+
+func deletions(s string) []string {
+	chars := []rune(s)
+	res := make([]string, 0, len(chars))
+	for i := range chars {
+		res = append(res, string(chars[:i])+string(chars[i+1:]))
+	}
+	return res
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
+
+func truncateRunes(s string, n int) string {
+	chars := []rune(s)
+	if len(chars) > n {
+		return string(chars[:n])
+	}
+	return s
+}
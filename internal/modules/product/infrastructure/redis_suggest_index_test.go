@@ -0,0 +1,209 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"bobshop/internal/modules/product/domain"
+)
+
+func TestDeletions(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: []string{}},
+		{in: "a", want: []string{""}},
+		{in: "abc", want: []string{"bc", "ac", "ab"}},
+		{in: "áo", want: []string{"o", "á"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := deletions(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deletions(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "short word has prefixes only",
+			text:    "Áo",
+			want:    []string{"suggest:prefix:a", "suggest:prefix:ao"},
+			notWant: []string{"suggest:fuzzy:a", "suggest:fuzzy:o"},
+		},
+		{
+			name: "later words start phrases",
+			text: "Áo thun",
+			want: []string{
+				"suggest:prefix:ao thun",
+				"suggest:prefix:t",
+				"suggest:prefix:thun",
+				"suggest:fuzzy:hun",
+				"suggest:fuzzy:thu",
+			},
+			notWant: []string{"suggest:prefix:hun", "suggest:prefix:o thun"},
+		},
+		{
+			name:    "prefixes stop at the maximum length",
+			text:    "abcdefghijklmnopqrstuvwxyz",
+			want:    []string{"suggest:prefix:abcdefghijklmnopqrst"},
+			notWant: []string{"suggest:prefix:abcdefghijklmnopqrstu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestKeys(tt.text)
+			for _, key := range tt.want {
+				if !slices.Contains(got, key) {
+					t.Errorf("suggestKeys(%q) is missing %q", tt.text, key)
+				}
+			}
+			for _, key := range tt.notWant {
+				if slices.Contains(got, key) {
+					t.Errorf("suggestKeys(%q) has %q", tt.text, key)
+				}
+			}
+			seen := map[string]bool{}
+			for _, key := range got {
+				if seen[key] {
+					t.Errorf("suggestKeys(%q) repeats %q", tt.text, key)
+				}
+				seen[key] = true
+			}
+		})
+	}
+}
+
+// TestFuzzyLookup checks that a query reaches an indexed text through one of
+// the keys Suggest reads, that is, the two share a key.
+func TestFuzzyLookup(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		exact bool
+		fuzzy bool
+	}{
+		{name: "prefix", text: "Điện thoại", query: "dien", exact: true},
+		{name: "later word", text: "Điện thoại", query: "thoai", exact: true},
+		{name: "substituted character", text: "Điện thoại", query: "dein", fuzzy: true},
+		{name: "missing character", text: "Điện thoại", query: "dien thai", fuzzy: true},
+		{name: "extra character", text: "Điện thoại", query: "dieen", fuzzy: true},
+		{name: "short query has no typo matching", text: "Điện thoại", query: "dei"},
+		{name: "two edits away", text: "Điện thoại", query: "dxxn thoai"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexed := suggestKeys(tt.text)
+			exactKey, fuzzyKeys := lookupKeys(tt.query)
+			exact := slices.Contains(indexed, exactKey)
+			fuzzy := slices.ContainsFunc(fuzzyKeys, func(key string) bool { return slices.Contains(indexed, key) })
+			if exact != tt.exact {
+				t.Errorf("exact match = %v, want %v", exact, tt.exact)
+			}
+			if !tt.exact && fuzzy != tt.fuzzy {
+				t.Errorf("fuzzy match = %v, want %v", fuzzy, tt.fuzzy)
+			}
+		})
+	}
+}
+
+func TestReplacePlan(t *testing.T) {
+	productID := uuid.New()
+	entryKey := buildSuggestEntryKey(productID)
+	name := domain.Suggestion{Kind: domain.SuggestionProduct, Text: "Áo", ProductID: &productID}
+	brand := domain.Suggestion{Kind: domain.SuggestionBrand, Text: "Bob"}
+	old := suggestEntry{Suggestions: []domain.Suggestion{name, brand}, Sales: 3}
+	oldRecord, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		prev       string
+		next       *suggestEntry
+		wantRemove int
+		wantAdd    int
+		wantRecord bool
+	}{
+		{
+			name:       "first index",
+			next:       &suggestEntry{Suggestions: []domain.Suggestion{name, brand}, Sales: 5},
+			wantAdd:    2,
+			wantRecord: true,
+		},
+		{
+			name:       "reindex",
+			prev:       string(oldRecord),
+			next:       &suggestEntry{Suggestions: []domain.Suggestion{name}},
+			wantRemove: 2,
+			wantAdd:    1,
+			wantRecord: true,
+		},
+		{name: "remove", prev: string(oldRecord), wantRemove: 2},
+		{name: "remove never indexed", wantRemove: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, args, err := replacePlan(entryKey, tt.prev, tt.next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys[0] != entryKey || keys[1] != suggestRefsKey {
+				t.Fatalf("keys start %q, want the entry and refs keys", keys[:2])
+			}
+			if args[0] != tt.prev {
+				t.Errorf("expected entry = %q, want %q", args[0], tt.prev)
+			}
+			if record := args[1].(string); (record != "") != tt.wantRecord {
+				t.Errorf("record = %q, want recorded %v", record, tt.wantRecord)
+			}
+
+			var plan suggestPlan
+			if err := json.Unmarshal([]byte(args[2].(string)), &plan); err != nil {
+				t.Fatal(err)
+			}
+			if plan.Remove == nil || plan.Add == nil {
+				t.Fatalf("plan = %+v, want lists rather than null for the script", plan)
+			}
+			if len(plan.Remove) != tt.wantRemove || len(plan.Add) != tt.wantAdd {
+				t.Fatalf("plan removes %d and adds %d, want %d and %d",
+					len(plan.Remove), len(plan.Add), tt.wantRemove, tt.wantAdd)
+			}
+			for _, op := range append(plan.Remove, plan.Add...) {
+				var suggestion domain.Suggestion
+				if err := json.Unmarshal([]byte(op.Member), &suggestion); err != nil {
+					t.Fatal(err)
+				}
+				if op.Shared != (suggestion.Kind != domain.SuggestionProduct) {
+					t.Errorf("%s shared = %v", suggestion.Text, op.Shared)
+				}
+				var opKeys []string
+				for _, i := range op.Keys {
+					opKeys = append(opKeys, keys[i-1])
+				}
+				if want := suggestKeys(suggestion.Text); !reflect.DeepEqual(opKeys, want) {
+					t.Errorf("%s keys = %q, want %q", suggestion.Text, opKeys, want)
+				}
+			}
+			for _, op := range plan.Remove {
+				if op.Score != float64(old.Sales) {
+					t.Errorf("removal score = %v, want the recorded sales %d", op.Score, old.Sales)
+				}
+			}
+		})
+	}
+}
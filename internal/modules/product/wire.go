@@ -11,9 +11,14 @@ import (
 
 var ProductSet = wire.NewSet(
 	wire.Bind(new(domain.ProductRepository), new(*infrastructure.MongoProductRepository)),
+	wire.Bind(new(domain.Searcher), new(*infrastructure.MongoSearcher)),
+	wire.Bind(new(domain.SuggestIndex), new(*infrastructure.RedisSuggestIndex)),
 	wire.Bind(new(domain.Cache), new(*infrastructure.RedisCache)),
 	infrastructure.NewMongoProductRepository,
+	infrastructure.NewMongoSearcher,
 	infrastructure.NewRedisCache,
+	infrastructure.NewRedisSuggestIndex,
 	application.NewProductService,
+	application.NewProductDataSubject,
 	http.NewProductHandler,
 )
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -17,9 +19,28 @@ type Config struct {
 
 	// Web
 	Cookie CookieConfig `mapstructure:"cookie"`
+	CSRF   CSRFConfig   `mapstructure:"csrf"`
 
 	// JWT
 	JWT JWTConfig `mapstructure:"jwt"`
+
+	// Auth
+	Auth AuthConfig `mapstructure:"auth"`
+
+	// Mail
+	Mail MailConfig `mapstructure:"mail"`
+
+	// OpenID Connect
+	OIDC OIDCConfig `mapstructure:"oidc"`
+
+	// Data subject requests
+	Privacy PrivacyConfig `mapstructure:"privacy"`
+
+	// Listing cursors
+	Pagination PaginationConfig `mapstructure:"pagination"`
+
+	// Product search
+	Search SearchConfig `mapstructure:"search"`
 }
 
 type ServerConfig struct {
@@ -45,15 +66,161 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret          string `mapstructure:"secret"`
-	ExpirationHours string `mapstructure:"expiration_hours"`
+	Secret                 string        `mapstructure:"secret"`
+	ExpirationHours        string        `mapstructure:"expiration_hours"`
+	RefreshExpirationHours time.Duration `mapstructure:"refresh_expiration_hours"`
+	Issuer                 string        `mapstructure:"issuer"`
+	Audience               string        `mapstructure:"audience"`
+
+	// Asymmetric signing. When Keys is empty tokens are signed with Secret
+	// using HS256.
+	SigningKeyID string         `mapstructure:"signing_key_id"`
+	Keys         []JWTKeyConfig `mapstructure:"keys"`
+}
+
+// JWTKeyConfig points at an RSA or Ed25519 key pair in PEM files. Retired keys
+// keep only PublicKeyFile so tokens they signed still verify.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"kid"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+type AuthConfig struct {
+	TokenSecret           string        `mapstructure:"token_secret"`
+	LinkBaseURL           string        `mapstructure:"link_base_url"`
+	VerificationTokenTTL  time.Duration `mapstructure:"verification_token_ttl"`
+	PasswordResetTokenTTL time.Duration `mapstructure:"password_reset_token_ttl"`
+
+	// Sign-in throttling; a threshold of 0 turns that lockout off.
+	MaxFailedAttempts      int64         `mapstructure:"max_failed_attempts"`
+	MaxFailedAttemptsPerIP int64         `mapstructure:"max_failed_attempts_per_ip"`
+	FailedAttemptWindow    time.Duration `mapstructure:"failed_attempt_window"`
+	LockoutDuration        time.Duration `mapstructure:"lockout_duration"`
+	MaxLockoutDuration     time.Duration `mapstructure:"max_lockout_duration"`
+
+	// Two-factor authentication
+	MFAIssuer       string        `mapstructure:"mfa_issuer"`
+	MFATokenTTL     time.Duration `mapstructure:"mfa_token_ttl"`
+	RequireAdminMFA bool          `mapstructure:"require_admin_mfa"`
+
+	// Magic link sign-in; defaults to a 15 minute link and 5 requests per
+	// email per hour.
+	MagicLinkTokenTTL      string `mapstructure:"magic_link_token_ttl"`
+	MagicLinkMaxRequests   int64  `mapstructure:"magic_link_max_requests"`
+	MagicLinkRequestWindow string `mapstructure:"magic_link_request_window"`
+
+	// ImpersonationTTL bounds an admin impersonation session; defaults to
+	// 30 minutes.
+	ImpersonationTTL string `mapstructure:"impersonation_ttl"`
+
+	// AuditRetention is how long auth events are kept; defaults to 90 days.
+	// Admin actions follow AdminAuditRetention instead, which keeps them
+	// forever when unset.
+	AuditRetention      string `mapstructure:"audit_retention"`
+	AdminAuditRetention string `mapstructure:"admin_audit_retention"`
+
+	// Password hashing and strength
+	PasswordHashing PasswordHashingConfig `mapstructure:"password_hashing"`
+	PasswordPolicy  PasswordPolicyConfig  `mapstructure:"password_policy"`
+
+	// Passkeys
+	WebAuthn WebAuthnConfig `mapstructure:"webauthn"`
+}
+
+// WebAuthnConfig identifies this site to authenticators. RPID is the
+// registrable domain (e.g. "shop.example.com") and Origins the exact origins
+// the browser may report. UserVerification is "required", "preferred" (the
+// default) or "discouraged"; ChallengeTTL defaults to 5 minutes.
+type WebAuthnConfig struct {
+	RPID             string   `mapstructure:"rp_id"`
+	RPName           string   `mapstructure:"rp_name"`
+	Origins          []string `mapstructure:"origins"`
+	UserVerification string   `mapstructure:"user_verification"`
+	ChallengeTTL     string   `mapstructure:"challenge_ttl"`
+}
+
+// PasswordPolicyConfig defaults to a minimum of 8 and a maximum of 128
+// characters with no character class requirements.
+type PasswordPolicyConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	MaxLength     int  `mapstructure:"max_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+	DisallowEmail bool `mapstructure:"disallow_email"`
+	// BreachedPasswordsDir holds the SHA-1 prefix files; empty disables the check.
+	BreachedPasswordsDir string `mapstructure:"breached_passwords_dir"`
+}
+
+// PasswordHashingConfig picks the algorithm for new password hashes; Memory is
+// in KiB. Unset values fall back to the defaults in pkg/auth.
+type PasswordHashingConfig struct {
+	Algorithm   string `mapstructure:"algorithm"`
+	BcryptCost  int    `mapstructure:"bcrypt_cost"`
+	Memory      uint32 `mapstructure:"memory"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+type OIDCConfig struct {
+	// RedirectBaseURL is the public origin of this API; callbacks are served
+	// under /api/v1/auth/oidc/:provider/callback and mailed magic links point
+	// at /api/v1/auth/magic-link/callback. PostLoginRedirect is also where
+	// magic link sign-ins land.
+	RedirectBaseURL   string                        `mapstructure:"redirect_base_url"`
+	PostLoginRedirect string                        `mapstructure:"post_login_redirect"`
+	StateTTL          string                        `mapstructure:"state_ttl"`
+	Providers         map[string]OIDCProviderConfig `mapstructure:"providers"`
+}
+
+type OIDCProviderConfig struct {
+	IssuerURL    string   `mapstructure:"issuer_url"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+type PrivacyConfig struct {
+	DeletionGracePeriod   string `mapstructure:"deletion_grace_period"`
+	DeletionCheckInterval string `mapstructure:"deletion_check_interval"`
+}
+
+// PaginationConfig holds the key list cursors are signed with.
+type PaginationConfig struct {
+	CursorSecret string `mapstructure:"cursor_secret"`
+}
+
+// SearchConfig sets how often the search suggestions are rebuilt, which also
+// re-ranks them by current sales.
+type SearchConfig struct {
+	SuggestRefreshInterval time.Duration `mapstructure:"suggest_refresh_interval"`
+}
+
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
 }
 
 type CookieConfig struct {
-	HttpOnly bool   `mapstructure:"http_only"`
-	Secure   bool   `mapstructure:"secure"`
-	SameSite string `mapstructure:"same_site"`
-	MaxAge   string `mapstructure:"max_age"`
+	HttpOnly      bool   `mapstructure:"http_only"`
+	Secure        bool   `mapstructure:"secure"`
+	SameSite      string `mapstructure:"same_site"`
+	MaxAge        string `mapstructure:"max_age"`
+	RefreshMaxAge string `mapstructure:"refresh_max_age"`
+}
+
+// CSRFConfig lists the origins allowed to make cookie-authenticated writes,
+// e.g. "https://shop.example.com". At least one is required.
+type CSRFConfig struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
 }
 
 func IsDevelopment() bool {
@@ -76,6 +243,7 @@ func LoadConfig(envPath, configPath string) (*Config, error) {
 
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	setDefaults()
 
 	if IsDevelopment() {
 		viper.SetConfigFile(envPath)
@@ -85,10 +253,47 @@ func LoadConfig(envPath, configPath string) (*Config, error) {
 		}
 	}
 
+	// Durations are parsed here, once, so a malformed value stops startup.
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unable to decode into struct: %w", err)
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return &cfg, nil
 }
+
+func (c *Config) validate() error {
+	auth := c.Auth
+	if auth.MaxFailedAttempts < 0 || auth.MaxFailedAttemptsPerIP < 0 {
+		return errors.New("auth.max_failed_attempts and auth.max_failed_attempts_per_ip must not be negative")
+	}
+	if auth.FailedAttemptWindow <= 0 || auth.LockoutDuration <= 0 {
+		return errors.New("auth.failed_attempt_window and auth.lockout_duration must be positive")
+	}
+	if auth.MaxLockoutDuration < auth.LockoutDuration {
+		return errors.New("auth.max_lockout_duration must not be shorter than auth.lockout_duration")
+	}
+	if len(c.CSRF.AllowedOrigins) == 0 {
+		return errors.New("csrf.allowed_origins must list at least one origin")
+	}
+	if c.Search.SuggestRefreshInterval <= 0 {
+		return errors.New("search.suggest_refresh_interval must be positive")
+	}
+	return nil
+}
+
+func setDefaults() {
+	viper.SetDefault("jwt.refresh_expiration_hours", 7*24*time.Hour)
+	viper.SetDefault("auth.verification_token_ttl", 24*time.Hour)
+	viper.SetDefault("auth.password_reset_token_ttl", time.Hour)
+	viper.SetDefault("auth.mfa_token_ttl", 5*time.Minute)
+	viper.SetDefault("auth.max_failed_attempts", 5)
+	viper.SetDefault("auth.max_failed_attempts_per_ip", 50)
+	viper.SetDefault("auth.failed_attempt_window", 15*time.Minute)
+	viper.SetDefault("auth.lockout_duration", time.Minute)
+	viper.SetDefault("auth.max_lockout_duration", time.Hour)
+	viper.SetDefault("search.suggest_refresh_interval", time.Hour)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			Auth: AuthConfig{
+				MaxFailedAttempts:   5,
+				FailedAttemptWindow: 15 * time.Minute,
+				LockoutDuration:     time.Minute,
+				MaxLockoutDuration:  time.Hour,
+			},
+			CSRF:   CSRFConfig{AllowedOrigins: []string{"https://shop.example.com"}},
+			Search: SearchConfig{SuggestRefreshInterval: time.Hour},
+		}
+	}
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(*Config) {}},
+		{name: "lockout off", mutate: func(c *Config) { c.Auth.MaxFailedAttempts = 0 }},
+		{name: "negative threshold", mutate: func(c *Config) { c.Auth.MaxFailedAttemptsPerIP = -1 }, wantErr: true},
+		{name: "zero window", mutate: func(c *Config) { c.Auth.FailedAttemptWindow = 0 }, wantErr: true},
+		{name: "max below base", mutate: func(c *Config) { c.Auth.MaxLockoutDuration = time.Second }, wantErr: true},
+		{name: "no CSRF origins", mutate: func(c *Config) { c.CSRF.AllowedOrigins = nil }, wantErr: true},
+		{name: "no suggest refresh", mutate: func(c *Config) { c.Search.SuggestRefreshInterval = 0 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(&cfg)
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
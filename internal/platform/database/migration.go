@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const migrationsCollection = "schema_migrations"
+
+// Migration is a one-off change to stored data or indexes. Modules list theirs
+// and cmd/migrate applies the ones not yet recorded, so the server never
+// builds indexes or rewrites documents while starting up.
+//
+// IDs start with the date the migration was written (20060102_name) and are
+// applied in ID order. A failed migration is not recorded and runs again in
+// full on the next attempt, so Up must be safe to repeat.
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+type migrationRecord struct {
+	ID        string    `bson:"_id"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// RunMigrations applies the pending migrations in order and stops at the
+// first failure.
+func RunMigrations(ctx context.Context, db *mongo.Database, migrations []Migration) error {
+	collection := db.Collection(migrationsCollection)
+	applied, err := appliedMigrations(ctx, collection)
+	if err != nil {
+		return err
+	}
+	todo, err := pendingMigrations(migrations, applied)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range todo {
+		log.Printf("migration: applying %s: %s", m.ID, m.Description)
+		if err := m.Up(ctx, db); err != nil {
+			return fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+		if _, err := collection.InsertOne(ctx, migrationRecord{ID: m.ID, AppliedAt: time.Now()}); err != nil {
+			return fmt.Errorf("recording migration %s: %w", m.ID, err)
+		}
+	}
+	log.Printf("migration: %d applied, %d already up to date", len(todo), len(migrations)-len(todo))
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, collection *mongo.Collection) (map[string]bool, error) {
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []migrationRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(records))
+	for _, record := range records {
+		applied[record.ID] = true
+	}
+	return applied, nil
+}
+
+// pendingMigrations returns the migrations not applied yet, in ID order.
+func pendingMigrations(migrations []Migration, applied map[string]bool) ([]Migration, error) {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	var todo []Migration
+	for i, m := range sorted {
+		if m.ID == "" || m.Up == nil {
+			return nil, errors.New("migration is missing its ID or Up")
+		}
+		if i > 0 && sorted[i-1].ID == m.ID {
+			return nil, fmt.Errorf("duplicate migration %s", m.ID)
+		}
+		if !applied[m.ID] {
+			todo = append(todo, m)
+		}
+	}
+	return todo, nil
+}
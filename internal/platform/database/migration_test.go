@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	up := func(context.Context, *mongo.Database) error { return nil }
+	a := Migration{ID: "20260101_a", Up: up}
+	b := Migration{ID: "20260102_b", Up: up}
+	c := Migration{ID: "20260103_c", Up: up}
+
+	tests := []struct {
+		name       string
+		migrations []Migration
+		applied    map[string]bool
+		want       []string
+		wantErr    bool
+	}{
+		{name: "none applied", migrations: []Migration{a, b, c}, want: []string{a.ID, b.ID, c.ID}},
+		{name: "sorted by id", migrations: []Migration{c, a, b}, want: []string{a.ID, b.ID, c.ID}},
+		{name: "applied skipped", migrations: []Migration{a, b, c}, applied: map[string]bool{a.ID: true, c.ID: true}, want: []string{b.ID}},
+		{name: "up to date", migrations: []Migration{a, b}, applied: map[string]bool{a.ID: true, b.ID: true}},
+		{name: "duplicate id", migrations: []Migration{a, b, a}, wantErr: true},
+		{name: "missing id", migrations: []Migration{{Up: up}}, wantErr: true},
+		{name: "missing up", migrations: []Migration{{ID: "20260104_d"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pendingMigrations(tt.migrations, tt.applied)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pendingMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ids []string
+			for _, m := range got {
+				ids = append(ids, m.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("pendingMigrations() = %q, want %q", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bobshop/internal/platform/config"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// writePEM writes the key in PEM form into dir and returns the file path.
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// keyConfig writes the key pair to files and returns its configuration. A
+// retired key keeps only its public half.
+func keyConfig(t *testing.T, kid string, key crypto.Signer, retired bool) config.JWTKeyConfig {
+	t.Helper()
+	dir := t.TempDir()
+	if retired {
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config.JWTKeyConfig{ID: kid, PublicKeyFile: writePEM(t, dir, kid+".pub.pem", "PUBLIC KEY", der)}
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config.JWTKeyConfig{ID: kid, PrivateKeyFile: writePEM(t, dir, kid+".pem", "PRIVATE KEY", der)}
+}
+
+func TestLoadJwtKeys(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	tests := []struct {
+		name    string
+		cfgs    func(t *testing.T) []config.JWTKeyConfig
+		wantAlg map[string]string
+		wantErr bool
+	}{
+		{
+			name: "rsa and ed25519",
+			cfgs: func(t *testing.T) []config.JWTKeyConfig {
+				return []config.JWTKeyConfig{
+					keyConfig(t, "rsa", rsaKey, false),
+					keyConfig(t, "ed", newEd25519Key(t), true),
+				}
+			},
+			wantAlg: map[string]string{"rsa": "RS256", "ed": "EdDSA"},
+		},
+		{
+			name: "pkcs1 private key",
+			cfgs: func(t *testing.T) []config.JWTKeyConfig {
+				path := writePEM(t, t.TempDir(), "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+				return []config.JWTKeyConfig{{ID: "rsa", PrivateKeyFile: path}}
+			},
+			wantAlg: map[string]string{"rsa": "RS256"},
+		},
+		{
+			name: "missing kid",
+			cfgs: func(t *testing.T) []config.JWTKeyConfig {
+				return []config.JWTKeyConfig{keyConfig(t, "", rsaKey, false)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate kid",
+			cfgs: func(t *testing.T) []config.JWTKeyConfig {
+				return []config.JWTKeyConfig{keyConfig(t, "a", rsaKey, false), keyConfig(t, "a", rsaKey, true)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "no key file",
+			cfgs: func(*testing.T) []config.JWTKeyConfig {
+				return []config.JWTKeyConfig{{ID: "a"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "not pem",
+			cfgs: func(t *testing.T) []config.JWTKeyConfig {
+				path := filepath.Join(t.TempDir(), "key.pem")
+				if err := os.WriteFile(path, []byte("secret"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: path}}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := loadJwtKeys(tt.cfgs(t))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadJwtKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantAlg) {
+				t.Fatalf("loaded %d keys, want %d", len(keys), len(tt.wantAlg))
+			}
+			for kid, alg := range tt.wantAlg {
+				if got := keys[kid].method.Alg(); got != alg {
+					t.Errorf("key %q alg = %s, want %s", kid, got, alg)
+				}
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, edKey := newRSAKey(t), newEd25519Key(t)
+	tokenizer, err := NewJwtTokenizer(&config.JWTConfig{
+		SigningKeyID: "2024-rsa",
+		Keys: []config.JWTKeyConfig{
+			keyConfig(t, "2024-rsa", rsaKey, false),
+			keyConfig(t, "2023-ed", edKey, true),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := tokenizer.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS() has %d keys, want 2", len(set.Keys))
+	}
+	ed, rsaJWK := set.Keys[0], set.Keys[1]
+
+	if ed.KeyID != "2023-ed" || ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != "EdDSA" || ed.Use != "sig" {
+		t.Errorf("ed25519 key = %+v", ed)
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(ed.X); !edKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Error("ed25519 key x does not match the public key")
+	}
+
+	if rsaJWK.KeyID != "2024-rsa" || rsaJWK.KeyType != "RSA" || rsaJWK.Algorithm != "RS256" || rsaJWK.Use != "sig" {
+		t.Errorf("rsa key = %+v", rsaJWK)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK.Modulus)
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK.Exponent)
+	if new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(rsaKey.E) {
+		t.Error("rsa key n and e do not match the public key")
+	}
+}
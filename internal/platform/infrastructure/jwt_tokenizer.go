@@ -1,41 +1,129 @@
 package infrastructure
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
+
 	jwt "github.com/golang-jwt/jwt/v5"
 
 	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/security"
 )
 
+// JwtTokenizer signs access tokens with the configured signing key and
+// verifies them against every configured key, so a new key can take over
+// signing while tokens from the previous one are still accepted. Without
+// keys it falls back to HS256 with the shared secret.
 type JwtTokenizer struct {
-	cfg *config.JWTConfig
+	cfg        *config.JWTConfig
+	keys       map[string]*jwtKey
+	signingKey *jwtKey
 }
 
-func NewJwtTokenizer(cfg *config.JWTConfig) *JwtTokenizer {
-	return &JwtTokenizer{cfg: cfg}
+func NewJwtTokenizer(cfg *config.JWTConfig) (*JwtTokenizer, error) {
+	keys, err := loadJwtKeys(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenizer := &JwtTokenizer{cfg: cfg, keys: keys}
+	if len(keys) > 0 {
+		signingKey, ok := keys[cfg.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q is not configured", cfg.SigningKeyID)
+		}
+		if signingKey.private == nil {
+			return nil, fmt.Errorf("signing key %q has no private key", cfg.SigningKeyID)
+		}
+		tokenizer.signingKey = signingKey
+	}
+	return tokenizer, nil
 }
 
-func (j *JwtTokenizer) GenerateToken(userID string, role string) (string, error) {
-	exp, err := time.ParseDuration(j.cfg.ExpirationHours)
-	if err != nil {
-		return "", err
+func (j *JwtTokenizer) GenerateToken(c security.Claims) (string, error) {
+	exp := c.ExpiresIn
+	if exp == 0 {
+		var err error
+		if exp, err = time.ParseDuration(j.cfg.ExpirationHours); err != nil {
+			return "", err
+		}
 	}
 	claims := jwt.MapClaims{
-		"sub":  userID,
-		"role": role,
-		"exp":  time.Now().Add(exp).Unix(),
-		"iat":  time.Now().Unix(),
+		"sub":            c.Subject,
+		"role":           c.Role,
+		"sid":            c.SessionID,
+		"email_verified": c.EmailVerified,
+		"mfa":            c.MFA,
+		"jti":            uuid.NewString(),
+		"exp":            time.Now().Add(exp).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+	if c.Actor != "" {
+		claims["act"] = map[string]any{"sub": c.Actor}
+	}
+	if j.cfg.Issuer != "" {
+		claims["iss"] = j.cfg.Issuer
+	}
+	if j.cfg.Audience != "" {
+		claims["aud"] = j.cfg.Audience
+	}
+
+	if j.signingKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.cfg.Secret))
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.cfg.Secret))
+	token := jwt.NewWithClaims(j.signingKey.method, claims)
+	token.Header["kid"] = j.signingKey.id
+	return token.SignedString(j.signingKey.private)
 }
 
 func (j *JwtTokenizer) ParseToken(token string) (map[string]any, error) {
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if j.cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.cfg.Issuer))
+	}
+	if j.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(j.cfg.Audience))
+	}
+
 	claims := jwt.MapClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+	_, err := jwt.ParseWithClaims(token, claims, j.keyFunc, opts...)
+	return claims, err
+}
+
+// keyFunc picks the verification key by kid and refuses tokens whose alg does
+// not match that key, which rules out algorithm confusion attacks.
+func (j *JwtTokenizer) keyFunc(token *jwt.Token) (any, error) {
+	if j.signingKey == nil {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
 		return []byte(j.cfg.Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
+
+func (j *JwtTokenizer) JWKS() security.JWKS {
+	set := security.JWKS{Keys: make([]security.JWK, 0, len(j.keys))}
+	for _, key := range j.keys {
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	sort.Slice(set.Keys, func(a, b int) bool {
+		return set.Keys[a].KeyID < set.Keys[b].KeyID
 	})
-	return claims, err
+	return set
 }
@@ -0,0 +1,208 @@
+package infrastructure
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/security"
+)
+
+func newTestTokenizer(t *testing.T, cfg config.JWTConfig) *JwtTokenizer {
+	t.Helper()
+	if cfg.ExpirationHours == "" {
+		cfg.ExpirationHours = "15m"
+	}
+	tokenizer, err := NewJwtTokenizer(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenizer
+}
+
+func TestJwtTokenizerRoundTrip(t *testing.T) {
+	rsaKey, edKey := newRSAKey(t), newEd25519Key(t)
+	tests := []struct {
+		name    string
+		cfg     config.JWTConfig
+		wantAlg string
+		wantKid string
+	}{
+		{name: "shared secret", cfg: config.JWTConfig{Secret: "test-secret"}, wantAlg: "HS256"},
+		{
+			name: "rsa",
+			cfg: config.JWTConfig{
+				SigningKeyID: "rsa",
+				Keys:         []config.JWTKeyConfig{keyConfig(t, "rsa", rsaKey, false)},
+			},
+			wantAlg: "RS256",
+			wantKid: "rsa",
+		},
+		{
+			name: "ed25519",
+			cfg: config.JWTConfig{
+				SigningKeyID: "ed",
+				Keys:         []config.JWTKeyConfig{keyConfig(t, "ed", edKey, false)},
+			},
+			wantAlg: "EdDSA",
+			wantKid: "ed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer := newTestTokenizer(t, tt.cfg)
+			token, err := tokenizer.GenerateToken(security.Claims{Subject: "user-1", Role: "admin", SessionID: "s-1", MFA: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header.Method.Alg() != tt.wantAlg {
+				t.Errorf("alg = %s, want %s", header.Method.Alg(), tt.wantAlg)
+			}
+			if kid, _ := header.Header["kid"].(string); kid != tt.wantKid {
+				t.Errorf("kid = %q, want %q", kid, tt.wantKid)
+			}
+
+			claims, err := tokenizer.ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims["sub"] != "user-1" || claims["role"] != "admin" || claims["sid"] != "s-1" || claims["mfa"] != true {
+				t.Errorf("claims = %v", claims)
+			}
+		})
+	}
+}
+
+func TestJwtTokenizerKeyRotation(t *testing.T) {
+	oldKey, newKey := newRSAKey(t), newEd25519Key(t)
+	before := newTestTokenizer(t, config.JWTConfig{
+		SigningKeyID: "old",
+		Keys:         []config.JWTKeyConfig{keyConfig(t, "old", oldKey, false)},
+	})
+	token, err := before.GenerateToken(security.Claims{Subject: "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    []config.JWTKeyConfig
+		wantErr bool
+	}{
+		{
+			name: "old key retired",
+			keys: []config.JWTKeyConfig{keyConfig(t, "new", newKey, false), keyConfig(t, "old", oldKey, true)},
+		},
+		{
+			name:    "old key removed",
+			keys:    []config.JWTKeyConfig{keyConfig(t, "new", newKey, false)},
+			wantErr: true,
+		},
+		{
+			name:    "other key under the old kid",
+			keys:    []config.JWTKeyConfig{keyConfig(t, "new", newKey, false), keyConfig(t, "old", newRSAKey(t), true)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := newTestTokenizer(t, config.JWTConfig{SigningKeyID: "new", Keys: tt.keys})
+			if _, err := after.ParseToken(token); (err != nil) != tt.wantErr {
+				t.Errorf("ParseToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJwtTokenizerRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	tokenizer := newTestTokenizer(t, config.JWTConfig{
+		Secret:       "test-secret",
+		SigningKeyID: "rsa",
+		Keys:         []config.JWTKeyConfig{keyConfig(t, "rsa", rsaKey, false)},
+	})
+
+	der, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	claims := jwt.MapClaims{"sub": "admin", "role": "admin", "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    any
+	}{
+		// The public key is no secret, so an HMAC over it must not verify.
+		{name: "hs256 with the public key", method: jwt.SigningMethodHS256, kid: "rsa", key: publicPEM},
+		{name: "hs256 with the public key der", method: jwt.SigningMethodHS256, kid: "rsa", key: der},
+		{name: "hs256 with the shared secret", method: jwt.SigningMethodHS256, kid: "rsa", key: []byte("test-secret")},
+		{name: "hs256 without kid", method: jwt.SigningMethodHS256, key: []byte("test-secret")},
+		{name: "none", method: jwt.SigningMethodNone, kid: "rsa", key: jwt.UnsafeAllowNoneSignatureType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(tt.method, claims)
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+			signed, err := token.SignedString(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tokenizer.ParseToken(signed); err == nil {
+				t.Error("ParseToken() accepted a token whose alg does not match its key")
+			}
+		})
+	}
+}
+
+func TestJwtTokenizerIssuerAudience(t *testing.T) {
+	verifier := newTestTokenizer(t, config.JWTConfig{Secret: "test-secret", Issuer: "bobshop", Audience: "bobshop-api"})
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+		wantErr  bool
+	}{
+		{name: "matching", issuer: "bobshop", audience: "bobshop-api"},
+		{name: "wrong issuer", issuer: "evil", audience: "bobshop-api", wantErr: true},
+		{name: "wrong audience", issuer: "bobshop", audience: "admin-api", wantErr: true},
+		{name: "no issuer", audience: "bobshop-api", wantErr: true},
+		{name: "no audience", issuer: "bobshop", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := newTestTokenizer(t, config.JWTConfig{Secret: "test-secret", Issuer: tt.issuer, Audience: tt.audience})
+			token, err := signer.GenerateToken(security.Claims{Subject: "user-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := verifier.ParseToken(token); (err != nil) != tt.wantErr {
+				t.Errorf("ParseToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJwtTokenizerExpiry(t *testing.T) {
+	tokenizer := newTestTokenizer(t, config.JWTConfig{Secret: "test-secret"})
+	token, err := tokenizer.GenerateToken(security.Claims{Subject: "user-1", ExpiresIn: -time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokenizer.ParseToken(token); err == nil {
+		t.Error("ParseToken() accepted an expired token")
+	}
+}
@@ -0,0 +1,33 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/mail"
+)
+
+// NewMailer picks the mail.Mailer implementation named by the mail driver.
+// The outbox is the default so development setups never send real mail;
+// production refuses to start without a working SMTP setup.
+func NewMailer(cfg *config.MailConfig, db *mongo.Database) (mail.Mailer, error) {
+	switch cfg.Driver {
+	case mail.DriverSMTP:
+		if cfg.Host == "" || cfg.Port == "" || cfg.From == "" {
+			return nil, errors.New("smtp mail driver needs a host, port and from address")
+		}
+		return NewSmtpMailer(cfg), nil
+	case mail.DriverOutbox, "":
+		if config.IsProduction() {
+			return nil, errors.New("mail driver must be smtp in production; the outbox never delivers")
+		}
+		log.Println("WARNING: mail is written to the outbox collection and never delivered")
+		return NewOutboxMailer(db), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"bobshop/internal/platform/mail"
+)
+
+type outboxMessage struct {
+	ID        uuid.UUID `bson:"_id"`
+	To        string    `bson:"to"`
+	Subject   string    `bson:"subject"`
+	Body      string    `bson:"body"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// OutboxMailer stores messages in the mail_outbox collection instead of
+// delivering them. Use it in development and tests to read what would have
+// been sent.
+type OutboxMailer struct {
+	collection *mongo.Collection
+}
+
+func NewOutboxMailer(db *mongo.Database) *OutboxMailer {
+	return &OutboxMailer{collection: db.Collection("mail_outbox")}
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg mail.Message) error {
+	_, err := m.collection.InsertOne(ctx, outboxMessage{
+		ID:        uuid.New(),
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Body:      msg.Body,
+		CreatedAt: time.Now(),
+	})
+	return err
+}
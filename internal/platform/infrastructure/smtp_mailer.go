@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/mail"
+)
+
+type SmtpMailer struct {
+	cfg *config.MailConfig
+}
+
+func NewSmtpMailer(cfg *config.MailConfig) *SmtpMailer {
+	return &SmtpMailer{cfg: cfg}
+}
+
+func (m *SmtpMailer) Send(ctx context.Context, msg mail.Message) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(msg.Body)
+
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	return smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, []byte(body.String()))
+}
@@ -1,22 +1,127 @@
 package middleware
 
 import (
+	"errors"
+	"slices"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
+	"bobshop/internal/platform/config"
 	"bobshop/internal/platform/response"
 	"bobshop/internal/platform/security"
 	"bobshop/internal/platform/web"
 )
 
-func AuthMiddleware(parser security.Tokenizer) gin.HandlerFunc {
+const (
+	authorizationHeader = "Authorization"
+	apiKeyHeader        = "X-API-Key"
+	bearerPrefix        = "Bearer "
+	impersonationHeader = "X-Impersonated-By"
+)
+
+var errMissingClaims = errors.New("token is missing required claims")
+
+// impersonationWrites are the only unsafe routes an impersonation token may
+// call: ending the impersonation and signing out.
+var impersonationWrites = []string{
+	"DELETE /api/v1/auth/impersonation",
+	"POST /api/v1/auth/signout",
+}
+
+// AuthMiddleware authenticates either a user, from a Bearer token or the
+// access_token cookie, or a service principal from the X-API-Key header. It
+// also notes whether the user's role must sign in with a second factor, which
+// RequireRole and RequirePermission enforce. Impersonation tokens are read-only
+// apart from impersonationWrites.
+func AuthMiddleware(
+	parser security.Tokenizer,
+	sessions security.SessionValidator,
+	apiKeys security.APIKeyAuthenticator,
+	authCfg *config.AuthConfig,
+) gin.HandlerFunc {
+	var mfaRequiredRoles []string
+	if authCfg.RequireAdminMFA {
+		mfaRequiredRoles = append(mfaRequiredRoles, security.RoleAdmin)
+	}
+
 	return func(c *gin.Context) {
-		token, _ := c.Cookie(web.AccessTokenCookieName)
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			authenticateAPIKey(c, apiKeys, key)
+			return
+		}
+
+		token := bearerToken(c)
+		if token == "" {
+			token, _ = c.Cookie(web.AccessTokenCookieName)
+		}
 		claims, err := parser.ParseToken(token)
 		if err != nil {
 			response.Unauthorized(c, err)
 			return
 		}
-		c.Set(web.UserIDKey, claims["sub"])
-		c.Set(web.RoleKey, claims["role"])
+
+		userID, _ := claims["sub"].(string)
+		sessionID, _ := claims["sid"].(string)
+		if userID == "" || sessionID == "" {
+			response.Unauthorized(c, errMissingClaims)
+			return
+		}
+		if err := sessions.ValidateSession(c.Request.Context(), sessionID, userID); err != nil {
+			if errors.Is(err, security.ErrSessionRevoked) {
+				response.Unauthorized(c, err)
+				return
+			}
+			response.InternalError(c, err)
+			return
+		}
+
+		role, _ := claims["role"].(string)
+		c.Set(web.UserIDKey, userID)
+		c.Set(web.RoleKey, role)
+		c.Set(web.MFARequiredKey, slices.Contains(mfaRequiredRoles, role))
+		c.Set(web.SessionIDKey, sessionID)
+		verified, _ := claims["email_verified"].(bool)
+		c.Set(web.VerifiedKey, verified)
+		mfa, _ := claims["mfa"].(bool)
+		c.Set(web.MFAKey, mfa)
+
+		// Every response to an impersonation token says so, so the support
+		// tool can show it.
+		if act, ok := claims["act"].(map[string]any); ok {
+			actorID, _ := act["sub"].(string)
+			if actorID == "" {
+				response.Unauthorized(c, errMissingClaims)
+				return
+			}
+			c.Set(web.ActorIDKey, actorID)
+			c.Header(impersonationHeader, actorID)
+			route := c.Request.Method + " " + c.FullPath()
+			if !isSafeMethod(c.Request.Method) && !slices.Contains(impersonationWrites, route) {
+				response.Forbidden(c, errImpersonating)
+				return
+			}
+		}
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeys security.APIKeyAuthenticator, key string) {
+	principal, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, security.ErrInvalidAPIKey) {
+			response.Unauthorized(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	c.Set(web.PrincipalKey, principal)
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader(authorizationHeader)
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return ""
 	}
+	return strings.TrimSpace(header[len(bearerPrefix):])
 }
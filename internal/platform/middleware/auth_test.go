@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/security"
+)
+
+type stubTokenizer struct {
+	security.Tokenizer
+	claims map[string]any
+}
+
+func (s stubTokenizer) ParseToken(string) (map[string]any, error) {
+	return s.claims, nil
+}
+
+type stubSessions struct{}
+
+func (stubSessions) ValidateSession(context.Context, string, string) error {
+	return nil
+}
+
+func TestAuthMiddlewareImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := map[string]any{"sub": "user", "sid": "session", "role": security.RoleUser}
+	impersonation := map[string]any{
+		"sub": "user", "sid": "session", "role": security.RoleUser,
+		"act": map[string]any{"sub": "admin"},
+	}
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		method string
+		path   string
+		want   int
+	}{
+		{name: "user write", claims: claims, method: http.MethodPatch, path: "/api/v1/me", want: http.StatusOK},
+		{name: "impersonation read", claims: impersonation, method: http.MethodGet, path: "/api/v1/me", want: http.StatusOK},
+		{name: "impersonation profile update", claims: impersonation, method: http.MethodPatch, path: "/api/v1/me", want: http.StatusForbidden},
+		{
+			name:   "impersonation review",
+			claims: impersonation,
+			method: http.MethodPost,
+			path:   "/api/v1/products/:id/reviews",
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "impersonation stop",
+			claims: impersonation,
+			method: http.MethodDelete,
+			path:   "/api/v1/auth/impersonation",
+			want:   http.StatusOK,
+		},
+		{
+			name:   "act claim without subject",
+			claims: map[string]any{"sub": "user", "sid": "session", "act": map[string]any{}},
+			method: http.MethodGet,
+			path:   "/api/v1/me",
+			want:   http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			auth := AuthMiddleware(stubTokenizer{claims: tt.claims}, stubSessions{}, nil, &config.AuthConfig{})
+			engine.Handle(tt.method, tt.path, auth, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+type stubAPIKeys map[string]*security.Principal
+
+func (s stubAPIKeys) AuthenticateAPIKey(_ context.Context, key string) (*security.Principal, error) {
+	principal, ok := s[key]
+	if !ok {
+		return nil, security.ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := stubAPIKeys{
+		"bsk_products":   {Name: "feed", Scopes: []security.Permission{security.PermProductWrite}},
+		"bsk_categories": {Name: "sync", Scopes: []security.Permission{security.PermCategoryWrite}},
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		route gin.HandlerFunc
+		want  int
+	}{
+		{name: "scoped key", key: "bsk_products", route: RequirePermission(security.PermProductWrite), want: http.StatusOK},
+		{name: "key without scope", key: "bsk_categories", route: RequirePermission(security.PermProductWrite), want: http.StatusForbidden},
+		{name: "scope outside the role model", key: "bsk_products", route: RequirePermission(security.PermUserManage), want: http.StatusForbidden},
+		{name: "user route", key: "bsk_products", route: RequireUser(), want: http.StatusForbidden},
+		{name: "unknown key", key: "bsk_revoked", route: RequirePermission(security.PermProductWrite), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			auth := AuthMiddleware(stubTokenizer{}, stubSessions{}, keys, &config.AuthConfig{})
+			engine.POST("/", auth, tt.route, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/response"
+	"bobshop/internal/platform/security"
+	"bobshop/internal/platform/web"
+)
+
+var (
+	errEmailNotVerified = errors.New("email address is not verified")
+	errMFARequired      = errors.New("two-factor authentication is required for this role")
+	errUserRequired     = errors.New("this endpoint requires a signed-in user")
+	errImpersonating    = errors.New("this action is not allowed while impersonating a user")
+)
+
+// The Require* middleware read what AuthMiddleware put in the context, so they
+// must be registered after it.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if web.GetPrincipal(c) != nil {
+			response.Forbidden(c, errUserRequired)
+			return
+		}
+		role := web.GetRole(c)
+		if !slices.Contains(roles, role) {
+			response.Forbidden(c, fmt.Errorf("role %q is not allowed", role))
+			return
+		}
+		requireMFA(c)
+	}
+}
+
+// BlockImpersonation keeps sensitive account actions out of reach of admins
+// impersonating a user.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if web.IsImpersonating(c) {
+			response.Forbidden(c, errImpersonating)
+			return
+		}
+	}
+}
+
+// RequirePermission checks the role of a user or the scopes of an API key.
+func RequirePermission(permission security.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal := web.GetPrincipal(c); principal != nil {
+			if !slices.Contains(principal.Scopes, permission) {
+				response.Forbidden(c, fmt.Errorf("api key %q lacks scope %q", principal.Name, permission))
+			}
+			return
+		}
+		role := web.GetRole(c)
+		if !security.HasPermission(role, permission) {
+			response.Forbidden(c, fmt.Errorf("role %q lacks permission %q", role, permission))
+			return
+		}
+		requireMFA(c)
+	}
+}
+
+// RequireUser rejects API key principals on routes that act on behalf of a
+// user account.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if web.GetPrincipal(c) != nil {
+			response.Forbidden(c, errUserRequired)
+			return
+		}
+	}
+}
+
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !web.IsEmailVerified(c) {
+			response.Forbidden(c, errEmailNotVerified)
+			return
+		}
+	}
+}
+
+func requireMFA(c *gin.Context) {
+	if web.IsMFARequired(c) && !web.IsMFAAuthenticated(c) {
+		response.Forbidden(c, errMFARequired)
+	}
+}
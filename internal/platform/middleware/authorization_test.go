@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/security"
+	"bobshop/internal/platform/web"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		role        string
+		mfaRequired bool
+		mfa         bool
+		principal   *security.Principal
+		want        int
+	}{
+		{name: "admin", role: security.RoleAdmin, want: http.StatusOK},
+		{name: "customer", role: security.RoleUser, want: http.StatusForbidden},
+		{name: "admin without required mfa", role: security.RoleAdmin, mfaRequired: true, want: http.StatusForbidden},
+		{name: "admin with required mfa", role: security.RoleAdmin, mfaRequired: true, mfa: true, want: http.StatusOK},
+		{
+			name:      "api key with scope",
+			principal: &security.Principal{Name: "feed", Scopes: []security.Permission{security.PermProductWrite}},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "api key without scope",
+			principal: &security.Principal{Name: "feed", Scopes: []security.Permission{security.PermCategoryWrite}},
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "api key ignores role",
+			role:      security.RoleAdmin,
+			principal: &security.Principal{Name: "feed"},
+			want:      http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.GET("/",
+				func(c *gin.Context) {
+					c.Set(web.RoleKey, tt.role)
+					c.Set(web.MFARequiredKey, tt.mfaRequired)
+					c.Set(web.MFAKey, tt.mfa)
+					if tt.principal != nil {
+						c.Set(web.PrincipalKey, tt.principal)
+					}
+				},
+				RequirePermission(security.PermProductWrite),
+				func(c *gin.Context) { c.Status(http.StatusOK) },
+			)
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
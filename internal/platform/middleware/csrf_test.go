@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"bobshop/internal/platform/config"
+	"bobshop/internal/platform/web"
+)
+
+func TestCSRFProtection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.CSRFConfig{AllowedOrigins: []string{"https://Shop.example.com/"}}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		cookies map[string]string
+		want    int
+	}{
+		{
+			name:   "safe method",
+			method: http.MethodGet,
+			cookies: map[string]string{
+				web.AccessTokenCookieName: "a",
+			},
+			want: http.StatusOK,
+		},
+		{
+			name:    "bearer token",
+			method:  http.MethodPost,
+			headers: map[string]string{"Authorization": "Bearer t", "Origin": "https://evil.example"},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "no auth cookies",
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "https://evil.example"},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "allowed origin and matching token",
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "https://shop.example.com", web.CSRFHeaderName: "tok"},
+			cookies: map[string]string{web.AccessTokenCookieName: "a", web.CSRFCookieName: "tok"},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "allowed referer",
+			method:  http.MethodDelete,
+			headers: map[string]string{"Referer": "https://shop.example.com/account", web.CSRFHeaderName: "tok"},
+			cookies: map[string]string{web.RefreshTokenCookieName: "r", web.CSRFCookieName: "tok"},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "other origin",
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "https://evil.example", web.CSRFHeaderName: "tok"},
+			cookies: map[string]string{web.AccessTokenCookieName: "a", web.CSRFCookieName: "tok"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "same host but not listed",
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "http://api.test", web.CSRFHeaderName: "tok"},
+			cookies: map[string]string{web.AccessTokenCookieName: "a", web.CSRFCookieName: "tok"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "missing token header",
+			method:  http.MethodPatch,
+			headers: map[string]string{"Origin": "https://shop.example.com"},
+			cookies: map[string]string{web.AccessTokenCookieName: "a", web.CSRFCookieName: "tok"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "token mismatch",
+			method:  http.MethodPost,
+			headers: map[string]string{web.CSRFHeaderName: "other"},
+			cookies: map[string]string{web.AccessTokenCookieName: "a", web.CSRFCookieName: "tok"},
+			want:    http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(CSRFProtection(cfg))
+			engine.Handle(tt.method, "/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "http://api.test/x", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			for k, v := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: k, Value: v})
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
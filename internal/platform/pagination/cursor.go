@@ -0,0 +1,66 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"bobshop/internal/platform/config"
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrMissingCursorKey = errors.New("pagination.cursor_secret must be set")
+)
+
+// CursorCodec turns a position in a listing into a token: the JSON payload and
+// an HMAC over it, both base64url-encoded. Clients can read a cursor but
+// cannot alter what it points at.
+type CursorCodec struct {
+	key []byte
+}
+
+func NewCursorCodec(cfg *config.PaginationConfig) (*CursorCodec, error) {
+	if cfg.CursorSecret == "" {
+		return nil, ErrMissingCursorKey
+	}
+	return &CursorCodec{key: []byte(cfg.CursorSecret)}, nil
+}
+
+func (c *CursorCodec) Encode(v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+func (c *CursorCodec) Decode(cursor string, v any) error {
+	encodedPayload, encodedSig, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte("cursor."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,87 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"bobshop/internal/platform/config"
+)
+
+type position struct {
+	Key int64  `json:"k"`
+	ID  string `json:"id"`
+}
+
+func newCodec(t *testing.T, secret string) *CursorCodec {
+	t.Helper()
+	codec, err := NewCursorCodec(&config.PaginationConfig{CursorSecret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codec
+}
+
+func TestNewCursorCodecRequiresSecret(t *testing.T) {
+	if _, err := NewCursorCodec(&config.PaginationConfig{}); !errors.Is(err, ErrMissingCursorKey) {
+		t.Errorf("NewCursorCodec() error = %v, want %v", err, ErrMissingCursorKey)
+	}
+}
+
+func TestCursorCodecRoundTrip(t *testing.T) {
+	codec := newCodec(t, "secret")
+	want := position{Key: 1999, ID: "0192b1c4-6f3a-7d2e-9a41-3c5e8f7b2d10"}
+
+	cursor, err := codec.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got position
+	if err := codec.Decode(cursor, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCursorCodecRejectsTampering(t *testing.T) {
+	codec := newCodec(t, "secret")
+	cursor, err := codec.Encode(position{Key: 1999, ID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, sig, _ := strings.Cut(cursor, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"k":1,"id":"a"}`))
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "no signature", cursor: payload},
+		{name: "altered payload", cursor: forged + "." + sig},
+		{name: "truncated signature", cursor: payload + "." + sig[:len(sig)-2]},
+		{name: "payload not base64", cursor: "!!!." + sig},
+		{name: "signature not base64", cursor: payload + ".!!!"},
+		{name: "signed with another secret", cursor: mustEncode(t, newCodec(t, "other"), position{Key: 1999, ID: "a"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got position
+			if err := codec.Decode(tt.cursor, &got); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) error = %v, want %v", tt.cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func mustEncode(t *testing.T, codec *CursorCodec, v any) string {
+	t.Helper()
+	cursor, err := codec.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cursor
+}
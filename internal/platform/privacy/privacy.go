@@ -0,0 +1,18 @@
+package privacy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DataSubject is implemented by every module that holds personal data, so a
+// user's data can be exported and erased without modules knowing about each
+// other.
+type DataSubject interface {
+	// Name keys the module's section of an export.
+	Name() string
+	ExportUserData(ctx context.Context, userID uuid.UUID) (any, error)
+	// EraseUserData must be idempotent: a failed erasure is retried in full.
+	EraseUserData(ctx context.Context, userID uuid.UUID) error
+}
@@ -67,6 +67,19 @@ func BadRequest(c *gin.Context, detail string, err error) {
 	Error(c, http.StatusBadRequest, "BAD_REQUEST", detail, err)
 }
 
+// ValidationFailed reports per-field problems. Unlike other errors the detail
+// is always sent, since it is meant for the client.
+func ValidationFailed(c *gin.Context, message string, fields any) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, Response{
+		Success: false,
+		Message: message,
+		Error: &APIError{
+			Code:   "VALIDATION_FAILED",
+			Detail: fields,
+		},
+	})
+}
+
 func Unauthorized(c *gin.Context, err error) {
 	Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized", err)
 }
@@ -83,6 +96,10 @@ func Conflict(c *gin.Context, detail string, err error) {
 	Error(c, http.StatusConflict, "CONFLICT", detail, err)
 }
 
+func TooManyRequests(c *gin.Context, detail string, err error) {
+	Error(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", detail, err)
+}
+
 func InternalError(c *gin.Context, err error) {
 	Error(c, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "Internal server error", err)
 }
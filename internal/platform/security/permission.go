@@ -0,0 +1,48 @@
+package security
+
+import "slices"
+
+type Permission string
+
+const (
+	PermProductWrite  Permission = "product:write"
+	PermCategoryWrite Permission = "category:write"
+	PermReviewWrite   Permission = "review:write"
+	PermUserManage    Permission = "user:manage"
+	PermAPIKeyManage  Permission = "api_key:manage"
+	PermAuditRead     Permission = "audit:read"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleUser: {
+		PermReviewWrite,
+	},
+	RoleAdmin: {
+		PermProductWrite,
+		PermCategoryWrite,
+		PermReviewWrite,
+		PermUserManage,
+		PermAPIKeyManage,
+		PermAuditRead,
+	},
+}
+
+// APIKeyScopes are the permissions that may be granted to API keys. Anything
+// tied to a human account stays out of reach of service principals.
+var APIKeyScopes = []Permission{
+	PermProductWrite,
+	PermCategoryWrite,
+}
+
+func IsAPIKeyScope(permission Permission) bool {
+	return slices.Contains(APIKeyScopes, permission)
+}
+
+func HasPermission(role string, permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
@@ -0,0 +1,21 @@
+package security
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// Principal is a non-human caller authenticated with an API key.
+type Principal struct {
+	ID     string
+	Name   string
+	Scopes []Permission
+}
+
+// APIKeyAuthenticator resolves the X-API-Key header to a principal. Unknown,
+// expired and revoked keys are reported as ErrInvalidAPIKey.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error)
+}
@@ -1,6 +1,33 @@
 package security
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSessionRevoked = errors.New("session revoked")
+
+type Claims struct {
+	Subject       string
+	Role          string
+	SessionID     string
+	EmailVerified bool
+	MFA           bool
+	// Actor is the admin acting as Subject, sent as the RFC 8693 act claim.
+	Actor string
+	// ExpiresIn overrides the configured access token lifetime when set.
+	ExpiresIn time.Duration
+}
+
 type Tokenizer interface {
-	GenerateToken(userID string, role string) (string, error)
+	GenerateToken(claims Claims) (string, error)
 	ParseToken(token string) (map[string]any, error)
 }
+
+// SessionValidator reports whether the session behind an access token is
+// still active, so revoked sessions are rejected before their tokens expire.
+// Inactive sessions are reported as ErrSessionRevoked.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID, userID string) error
+}
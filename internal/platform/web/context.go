@@ -3,12 +3,20 @@ package web
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"bobshop/internal/platform/security"
 )
 
 const (
-	UserIDKey  = "user_id"
-	RoleKey    = "role"
-	IDParamKey = "id"
+	UserIDKey      = "user_id"
+	RoleKey        = "role"
+	SessionIDKey   = "session_id"
+	VerifiedKey    = "email_verified"
+	MFAKey         = "mfa"
+	MFARequiredKey = "mfa_required"
+	PrincipalKey   = "principal"
+	ActorIDKey     = "actor_id"
+	IDParamKey     = "id"
 )
 
 func GetUserID(c *gin.Context) uuid.UUID {
@@ -19,6 +27,49 @@ func GetRole(c *gin.Context) string {
 	return c.GetString(RoleKey)
 }
 
+// GetSessionID returns the session behind a user token; API keys have none.
+func GetSessionID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString(SessionIDKey))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func IsEmailVerified(c *gin.Context) bool {
+	return c.GetBool(VerifiedKey)
+}
+
+func IsMFAAuthenticated(c *gin.Context) bool {
+	return c.GetBool(MFAKey)
+}
+
+// IsMFARequired reports whether the user's role may only act after signing in
+// with a second factor.
+func IsMFARequired(c *gin.Context) bool {
+	return c.GetBool(MFARequiredKey)
+}
+
+// GetPrincipal returns the API key principal, or nil for user requests.
+func GetPrincipal(c *gin.Context) *security.Principal {
+	principal, _ := c.Get(PrincipalKey)
+	p, _ := principal.(*security.Principal)
+	return p
+}
+
+// GetActorID returns the admin behind an impersonation token.
+func GetActorID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString(ActorIDKey))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func IsImpersonating(c *gin.Context) bool {
+	return c.GetString(ActorIDKey) != ""
+}
+
 func GetIDParam(c *gin.Context) (uuid.UUID, error) {
 	param := c.Param(IDParamKey)
 	id, err := uuid.Parse(param)
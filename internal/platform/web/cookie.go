@@ -8,7 +8,8 @@ import (
 )
 
 const (
-	AccessTokenCookieName = "access_token"
+	AccessTokenCookieName  = "access_token"
+	RefreshTokenCookieName = "refresh_token"
 )
 
 type CookieManager struct {
@@ -27,7 +28,15 @@ func (c *CookieManager) getSameSite() http.SameSite {
 }
 
 func (c *CookieManager) GetMaxAge() int {
-	cookieMaxAge, err := time.ParseDuration(c.cfg.MaxAge)
+	return parseMaxAge(c.cfg.MaxAge)
+}
+
+func (c *CookieManager) GetRefreshMaxAge() int {
+	return parseMaxAge(c.cfg.RefreshMaxAge)
+}
+
+func parseMaxAge(value string) int {
+	cookieMaxAge, err := time.ParseDuration(value)
 	if err != nil {
 		return 0
 	}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+)
+
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+
+	csrfTokenBytes = 32
+)
+
+func NewCSRFToken() (string, error) {
+	buf := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// BuildCSRFCookie is readable from scripts so a client can echo it back in
+// the X-CSRF-Token header, and covers the whole API rather than the path it
+// was issued on.
+func (c *CookieManager) BuildCSRFCookie(value string, maxAge int) *http.Cookie {
+	cookie := c.BuildCookie(CSRFCookieName, value, maxAge)
+	cookie.Path = "/"
+	cookie.HttpOnly = false
+	return cookie
+}
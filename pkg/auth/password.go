@@ -1,15 +1,182 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// PasswordHashParams selects the algorithm new hashes are created with.
+// Argon2Memory is in KiB.
+type PasswordHashParams struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+}
+
+var DefaultPasswordHashParams = PasswordHashParams{
+	Algorithm:         AlgorithmArgon2id,
+	BcryptCost:        bcrypt.DefaultCost,
+	Argon2Memory:      64 * 1024,
+	Argon2Iterations:  3,
+	Argon2Parallelism: 2,
+	Argon2SaltLength:  16,
+	Argon2KeyLength:   32,
+}
+
+var hashParams = DefaultPasswordHashParams
+
+var errMalformedHash = errors.New("malformed password hash")
+
+// ConfigurePasswordHashing sets the parameters for new hashes. Zero fields
+// keep their defaults. Existing hashes of any supported algorithm still verify.
+func ConfigurePasswordHashing(params PasswordHashParams) {
+	defaults := DefaultPasswordHashParams
+	if params.Algorithm == "" {
+		params.Algorithm = defaults.Algorithm
+	}
+	if params.BcryptCost == 0 {
+		params.BcryptCost = defaults.BcryptCost
+	}
+	if params.Argon2Memory == 0 {
+		params.Argon2Memory = defaults.Argon2Memory
+	}
+	if params.Argon2Iterations == 0 {
+		params.Argon2Iterations = defaults.Argon2Iterations
+	}
+	if params.Argon2Parallelism == 0 {
+		params.Argon2Parallelism = defaults.Argon2Parallelism
+	}
+	if params.Argon2SaltLength == 0 {
+		params.Argon2SaltLength = defaults.Argon2SaltLength
+	}
+	if params.Argon2KeyLength == 0 {
+		params.Argon2KeyLength = defaults.Argon2KeyLength
+	}
+	hashParams = params
+}
 
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+	if hashParams.Algorithm == AlgorithmBcrypt {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hashParams.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashedPassword), nil
 	}
-	return string(hashedPassword), nil
+	return hashArgon2id(password, hashParams)
 }
 
 func ComparePassword(hashedPassword, password string) bool {
+	if strings.HasPrefix(hashedPassword, "$"+AlgorithmArgon2id+"$") {
+		return compareArgon2id(hashedPassword, password)
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
 }
+
+// NeedsRehash reports whether a hash was made with a different algorithm or
+// weaker parameters than are currently configured.
+func NeedsRehash(hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$"+AlgorithmArgon2id+"$") {
+		if hashParams.Algorithm != AlgorithmArgon2id {
+			return true
+		}
+		hash, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return true
+		}
+		return hash.memory != hashParams.Argon2Memory ||
+			hash.iterations != hashParams.Argon2Iterations ||
+			hash.parallelism != hashParams.Argon2Parallelism ||
+			uint32(len(hash.key)) != hashParams.Argon2KeyLength
+	}
+
+	if hashParams.Algorithm != AlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != hashParams.BcryptCost
+}
+
+type argon2idHash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// hashArgon2id encodes in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func hashArgon2id(password string, params PasswordHashParams) (string, error) {
+	salt := make([]byte, params.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt,
+		params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, params.Argon2KeyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version,
+		params.Argon2Memory, params.Argon2Iterations, params.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func compareArgon2id(hashedPassword, password string) bool {
+	hash, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), hash.salt,
+		hash.iterations, hash.memory, hash.parallelism, uint32(len(hash.key)))
+	return subtle.ConstantTimeCompare(key, hash.key) == 1
+}
+
+func decodeArgon2id(hashedPassword string) (*argon2idHash, error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return nil, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, errMalformedHash
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	var hash argon2idHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.memory, &hash.iterations, &hash.parallelism); err != nil {
+		return nil, errMalformedHash
+	}
+	if hash.iterations == 0 || hash.parallelism == 0 {
+		return nil, errMalformedHash
+	}
+
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, errMalformedHash
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(hash.key) == 0 {
+		return nil, errMalformedHash
+	}
+	return &hash, nil
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2 keeps the tests quick; only the parameters matter, not the cost.
+var fastArgon2 = PasswordHashParams{
+	Algorithm:         AlgorithmArgon2id,
+	Argon2Memory:      64,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	Argon2SaltLength:  16,
+	Argon2KeyLength:   32,
+}
+
+func usePasswordHashing(t *testing.T, params PasswordHashParams) {
+	t.Helper()
+	previous := hashParams
+	t.Cleanup(func() { hashParams = previous })
+	ConfigurePasswordHashing(params)
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	usePasswordHashing(t, fastArgon2)
+
+	tests := []string{"correct horse battery staple", "", "mật khẩu", strings.Repeat("long", 100)}
+	for _, password := range tests {
+		t.Run(password, func(t *testing.T) {
+			hash, err := HashPassword(password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+				t.Errorf("HashPassword() = %q, want a PHC argon2id string with the configured parameters", hash)
+			}
+			if !ComparePassword(hash, password) {
+				t.Error("ComparePassword() rejected the password")
+			}
+			if ComparePassword(hash, password+"x") {
+				t.Error("ComparePassword() accepted a wrong password")
+			}
+			if NeedsRehash(hash) {
+				t.Error("NeedsRehash() = true for a hash with the current parameters")
+			}
+			if again, _ := HashPassword(password); again == hash {
+				t.Error("two hashes of one password share a salt")
+			}
+		})
+	}
+}
+
+func TestArgon2idRejectsMalformedHashes(t *testing.T) {
+	usePasswordHashing(t, fastArgon2)
+	valid, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, "$")
+	with := func(i int, value string) string {
+		p := append([]string(nil), parts...)
+		p[i] = value
+		return strings.Join(p, "$")
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "empty", hash: ""},
+		{name: "prefix only", hash: "$argon2id$"},
+		{name: "missing key", hash: strings.Join(parts[:5], "$")},
+		{name: "extra part", hash: valid + "$extra"},
+		{name: "other version", hash: with(2, "v=16")},
+		{name: "no version", hash: with(2, "19")},
+		{name: "no parameters", hash: with(3, "")},
+		{name: "zero iterations", hash: with(3, "m=64,t=0,p=1")},
+		{name: "zero parallelism", hash: with(3, "m=64,t=1,p=0")},
+		{name: "salt not base64", hash: with(4, "!!!")},
+		{name: "key not base64", hash: with(5, "!!!")},
+		{name: "empty key", hash: with(5, "")},
+		{name: "other key", hash: with(5, strings.Repeat("A", len(parts[5])))},
+		{name: "other salt", hash: with(4, strings.Repeat("A", len(parts[4])))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ComparePassword(tt.hash, "secret") {
+				t.Errorf("ComparePassword(%q) accepted the password", tt.hash)
+			}
+		})
+	}
+}
+
+func TestBcryptHashesStillVerify(t *testing.T) {
+	usePasswordHashing(t, fastArgon2)
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{name: "right password", password: "secret", want: true},
+		{name: "wrong password", password: "Secret"},
+		{name: "empty password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComparePassword(string(legacy), tt.password); got != tt.want {
+				t.Errorf("ComparePassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hashWith := func(t *testing.T, params PasswordHashParams) string {
+		t.Helper()
+		usePasswordHashing(t, params)
+		hash, err := HashPassword("secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	stronger := func(change func(p *PasswordHashParams)) PasswordHashParams {
+		p := fastArgon2
+		change(&p)
+		return p
+	}
+	bcryptAt := func(cost int) PasswordHashParams {
+		return PasswordHashParams{Algorithm: AlgorithmBcrypt, BcryptCost: cost}
+	}
+
+	tests := []struct {
+		name    string
+		created PasswordHashParams
+		current PasswordHashParams
+		want    bool
+	}{
+		{name: "argon2id unchanged", created: fastArgon2, current: fastArgon2},
+		{name: "more memory", created: fastArgon2, current: stronger(func(p *PasswordHashParams) { p.Argon2Memory = 128 }), want: true},
+		{name: "more iterations", created: fastArgon2, current: stronger(func(p *PasswordHashParams) { p.Argon2Iterations = 2 }), want: true},
+		{name: "more parallelism", created: fastArgon2, current: stronger(func(p *PasswordHashParams) { p.Argon2Parallelism = 2 }), want: true},
+		{name: "longer key", created: fastArgon2, current: stronger(func(p *PasswordHashParams) { p.Argon2KeyLength = 64 }), want: true},
+		{name: "bcrypt to argon2id", created: bcryptAt(bcrypt.MinCost), current: fastArgon2, want: true},
+		{name: "bcrypt unchanged", created: bcryptAt(bcrypt.MinCost), current: bcryptAt(bcrypt.MinCost)},
+		{name: "bcrypt higher cost", created: bcryptAt(bcrypt.MinCost), current: bcryptAt(bcrypt.MinCost + 1), want: true},
+		{name: "argon2id to bcrypt", created: fastArgon2, current: bcryptAt(bcrypt.MinCost), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := hashWith(t, tt.created)
+			usePasswordHashing(t, tt.current)
+			if got := NeedsRehash(hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+			if !ComparePassword(hash, "secret") {
+				t.Error("hash no longer verifies after the parameters changed")
+			}
+		})
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		usePasswordHashing(t, fastArgon2)
+		if !NeedsRehash("$argon2id$v=19$garbage") {
+			t.Error("NeedsRehash() = false for a malformed hash")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var foldMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// Fold lowercases s, strips diacritics and collapses whitespace, so "Áo  Đỏ"
+// and "ao do" compare equal. Đ has no decomposition and is mapped by hand.
+func Fold(s string) string {
+	folded, _, err := transform.String(foldMarks, strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	folded = strings.ReplaceAll(folded, "đ", "d")
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// Slug folds s into lowercase ASCII words joined by hyphens.
+func Slug(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range Fold(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
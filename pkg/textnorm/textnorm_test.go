@@ -0,0 +1,43 @@
+package textnorm
+
+import "testing"
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Áo  Đỏ", want: "ao do"},
+		{in: "  Điện\tthoại\n", want: "dien thoai"},
+		{in: "Crème Brûlée", want: "creme brulee"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Fold(tt.in); got != tt.want {
+				t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Giày dép Nữ", want: "giay-dep-nu"},
+		{in: "Đồ gia dụng", want: "do-gia-dung"},
+		{in: "  --Áo & Quần!! 2026 ", want: "ao-quan-2026"},
+		{in: "already-a-slug", want: "already-a-slug"},
+		{in: "日本", want: ""},
+		{in: "!!!", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Slug(tt.in); got != tt.want {
+				t.Errorf("Slug(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}